@@ -0,0 +1,270 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/geo/s2"
+)
+
+// LoadGPXTrack loads the points of all tracks and track segments of a GPX file (see LoadGPX) as a single track.
+func LoadGPXTrack(fileName string) ([]GPXPoint, error) {
+	d, err := LoadGPX(fileName)
+	if err != nil {
+		return nil, err
+	}
+	track := make([]GPXPoint, 0)
+	for _, t := range d.Tracks {
+		for _, segment := range t.Segments {
+			track = append(track, segment...)
+		}
+	}
+	return track, nil
+}
+
+// AnimationOptions specifies the frames and styling of a track animation
+type AnimationOptions struct {
+	// Frames is the number of frames; defaults to 50
+	Frames int
+	// Delay is the display duration of each frame; defaults to 100ms
+	Delay time.Duration
+	// ByTime reveals the track proportionally to its timestamps; the track is revealed proportionally to the distance, if
+	// ByTime is false or if the track lacks timestamps
+	ByTime bool
+	// Color and Weight specify the style of the revealed track
+	Color  color.Color
+	Weight float64
+	// HeadColor and HeadSize specify the style of the marker at the current track position
+	HeadColor color.Color
+	HeadSize  float64
+}
+
+// Animation is a sequence of frames with a common display duration
+type Animation struct {
+	Frames []*image.RGBA
+	Delay  time.Duration
+}
+
+// RenderAnimation renders an animation of the track being revealed, with a marker at the current position. The map
+// (tiles and the Context's map objects) is rendered only once, and its extent is chosen such that the whole track fits.
+func (m *Context) RenderAnimation(track []GPXPoint, opts AnimationOptions) (*Animation, error) {
+	if len(track) == 0 {
+		return nil, errors.New("cannot animate empty track")
+	}
+	opts = opts.withDefaults()
+	positions := make([]s2.LatLng, 0, len(track))
+	for _, pt := range track {
+		positions = append(positions, pt.Position)
+	}
+
+	// work on a shallow copy, such that the track is considered for the map extent, but not drawn to the base image
+	c := *m
+	c.objects = append(append([]MapObject{}, m.objects...), NewPath(positions, color.Transparent, opts.Weight+opts.HeadSize))
+	zoom, trans, err := c.croppedTransformer()
+	if err != nil {
+		return nil, err
+	}
+	bounds := image.Rect(0, 0, trans.pWidth, trans.pHeight)
+	base := image.NewRGBA(bounds)
+	if err := m.renderBase(base, zoom, trans); err != nil {
+		return nil, err
+	}
+	gc := gg.NewContextForRGBA(base)
+	for _, object := range m.objects {
+		object.Draw(gc, trans)
+	}
+
+	progress := trackProgress(track, opts.ByTime)
+	total := progress[len(progress)-1]
+	animation := &Animation{Delay: opts.Delay}
+	for i := 0; i < opts.Frames; i++ {
+		target := total
+		if opts.Frames > 1 {
+			target = total * float64(i) / float64(opts.Frames-1)
+		}
+		revealed := trackPrefix(positions, progress, target)
+
+		frame := image.NewRGBA(bounds)
+		copy(frame.Pix, base.Pix)
+		gc := gg.NewContextForRGBA(frame)
+		NewPath(revealed, opts.Color, opts.Weight).Draw(gc, trans)
+		x, y := trans.LatLngToXY(revealed[len(revealed)-1])
+		gc.DrawCircle(x, y, 0.5*opts.HeadSize)
+		gc.SetColor(opts.HeadColor)
+		gc.FillPreserve()
+		gc.SetColor(color.White)
+		gc.SetLineWidth(2.0)
+		gc.Stroke()
+		m.drawAttribution(gc, bounds)
+		animation.Frames = append(animation.Frames, frame)
+	}
+	return animation, nil
+}
+
+func (opts AnimationOptions) withDefaults() AnimationOptions {
+	if opts.Frames <= 0 {
+		opts.Frames = 50
+	}
+	if opts.Delay <= 0 {
+		opts.Delay = 100 * time.Millisecond
+	}
+	if opts.Color == nil {
+		opts.Color = color.RGBA{0xff, 0, 0, 0xff}
+	}
+	if opts.Weight <= 0 {
+		opts.Weight = 5.0
+	}
+	if opts.HeadColor == nil {
+		opts.HeadColor = color.RGBA{0, 0, 0xff, 0xff}
+	}
+	if opts.HeadSize <= 0 {
+		opts.HeadSize = 12.0
+	}
+	return opts
+}
+
+// trackProgress returns the elapsed seconds (if byTime is set and all points have increasing timestamps) or the travelled distance in meters for each track point
+func trackProgress(track []GPXPoint, byTime bool) []float64 {
+	progress := make([]float64, len(track))
+	useTime := byTime && !track[0].Time.IsZero()
+	for i := 1; i < len(track) && useTime; i++ {
+		useTime = !track[i].Time.Before(track[i-1].Time)
+	}
+	if useTime && track[len(track)-1].Time.After(track[0].Time) {
+		for i, pt := range track {
+			progress[i] = pt.Time.Sub(track[0].Time).Seconds()
+		}
+		return progress
+	}
+	for i := 1; i < len(track); i++ {
+		progress[i] = progress[i-1] + track[i-1].Position.Distance(track[i].Position).Radians()*earthRadius
+	}
+	return progress
+}
+
+// trackPrefix returns the positions with progress values up to target, plus the interpolated position at target
+func trackPrefix(positions []s2.LatLng, progress []float64, target float64) []s2.LatLng {
+	prefix := []s2.LatLng{positions[0]}
+	for i := 1; i < len(positions); i++ {
+		if progress[i] <= target {
+			prefix = append(prefix, positions[i])
+			continue
+		}
+		if d := progress[i] - progress[i-1]; d > 0 && target > progress[i-1] {
+			t := (target - progress[i-1]) / d
+			p := s2.Interpolate(t, s2.PointFromLatLng(positions[i-1]), s2.PointFromLatLng(positions[i]))
+			prefix = append(prefix, s2.LatLngFromPoint(p))
+		}
+		break
+	}
+	return prefix
+}
+
+// EncodeGIF writes the animation as looping animated GIF to w; all frames share a palette of 256 colors derived from the last frame.
+func (a *Animation) EncodeGIF(w io.Writer) error {
+	if len(a.Frames) == 0 {
+		return errors.New("cannot encode empty animation")
+	}
+	palette := quantize(a.Frames[len(a.Frames)-1], 256).Palette
+	delay := int(a.Delay / (10 * time.Millisecond))
+	anim := &gif.GIF{}
+	for _, frame := range a.Frames {
+		paletted := image.NewPaletted(frame.Bounds(), palette)
+		draw.FloydSteinberg.Draw(paletted, frame.Bounds(), frame, frame.Bounds().Min)
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// EncodeAPNG writes the animation as looping animated PNG to w; all frames are encoded as RGBA, such that they share
+// the PNG header.
+func (a *Animation) EncodeAPNG(w io.Writer) error {
+	if len(a.Frames) == 0 {
+		return errors.New("cannot encode empty animation")
+	}
+
+	var out bytes.Buffer
+	out.WriteString("\x89PNG\r\n\x1a\n")
+	size := a.Frames[0].Bounds().Size()
+	// 8 bit depth, color type RGBA, default compression and filter methods, no interlacing
+	writePNGChunk(&out, "IHDR", append(pngUint32s(uint32(size.X), uint32(size.Y)), 8, 6, 0, 0, 0))
+	writePNGChunk(&out, "acTL", pngUint32s(uint32(len(a.Frames)), 0))
+	sequence := uint32(0)
+	delay := uint16(a.Delay / (10 * time.Millisecond))
+	for i, frame := range a.Frames {
+		if frame.Bounds().Size() != size {
+			return fmt.Errorf("frame %d has a different size", i)
+		}
+		fcTL := append(pngUint32s(sequence, uint32(size.X), uint32(size.Y), 0, 0),
+			byte(delay>>8), byte(delay), 0, 100, // delay in 1/100 s
+			0, 0) // dispose op: none, blend op: source
+		writePNGChunk(&out, "fcTL", fcTL)
+		sequence++
+
+		data, err := pngImageData(frame)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			writePNGChunk(&out, "IDAT", data)
+		} else {
+			writePNGChunk(&out, "fdAT", append(pngUint32s(sequence), data...))
+			sequence++
+		}
+	}
+	writePNGChunk(&out, "IEND", nil)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// pngImageData returns the compressed, filtered RGBA scanlines of the image
+func pngImageData(img *image.RGBA) ([]byte, error) {
+	var buf bytes.Buffer
+	z := zlib.NewWriter(&buf)
+	filter := newPNGFilter(img.Rect.Dx())
+	for row := 0; row < img.Rect.Dy(); row++ {
+		if _, err := z.Write(filter.filter(img, row)); err != nil {
+			return nil, err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writePNGChunk(w io.Writer, typ string, data []byte) error {
+	chunk := make([]byte, 0, 12+len(data))
+	chunk = append(chunk, pngUint32s(uint32(len(data)))...)
+	chunk = append(chunk, typ...)
+	chunk = append(chunk, data...)
+	chunk = append(chunk, pngUint32s(crc32.ChecksumIEEE(chunk[4:]))...)
+	_, err := w.Write(chunk)
+	return err
+}
+
+func pngUint32s(values ...uint32) []byte {
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint32(data[4*i:], v)
+	}
+	return data
+}
@@ -0,0 +1,134 @@
+package sm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestRenderAnimation(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetSize(200, 150)
+	ctx.SetTileProvider(NewTileProviderNone())
+
+	start := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	track := []GPXPoint{
+		{Position: s2.LatLngFromDegrees(48.0, 7.0), Time: start},
+		{Position: s2.LatLngFromDegrees(48.1, 7.1), Time: start.Add(10 * time.Minute)},
+		{Position: s2.LatLngFromDegrees(48.0, 7.3), Time: start.Add(20 * time.Minute)},
+	}
+	animation, err := ctx.RenderAnimation(track, AnimationOptions{Frames: 5, ByTime: true})
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if len(animation.Frames) != 5 {
+		t.Fatalf("expected 5 frames, got %d", len(animation.Frames))
+	}
+
+	var buf bytes.Buffer
+	if err := animation.EncodeGIF(&buf); err != nil {
+		t.Fatalf("failed to encode GIF: %v", err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode GIF: %v", err)
+	}
+	if len(anim.Image) != 5 || anim.Delay[0] != 10 {
+		t.Errorf("unexpected GIF: %d frames, delay %d", len(anim.Image), anim.Delay[0])
+	}
+
+	buf.Reset()
+	if err := animation.EncodeAPNG(&buf); err != nil {
+		t.Fatalf("failed to encode APNG: %v", err)
+	}
+	data := buf.Bytes()
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("failed to decode APNG: %v", err)
+	}
+	chunks, err := pngChunks(data)
+	if err != nil {
+		t.Fatalf("invalid APNG: %v", err)
+	}
+	frames := 0
+	for _, chunk := range chunks {
+		if chunk.typ == "fcTL" {
+			frames++
+		}
+	}
+	if frames != 5 {
+		t.Errorf("expected 5 APNG frames, got %d", frames)
+	}
+}
+
+func TestEncodeAPNGOpaqueFrame(t *testing.T) {
+	transparent := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	transparent.Set(1, 1, color.RGBA{0x80, 0, 0, 0x80})
+	opaque := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	for i := range opaque.Pix {
+		opaque.Pix[i] = 0xff
+	}
+	animation := &Animation{Frames: []*image.RGBA{transparent, opaque}, Delay: 100 * time.Millisecond}
+
+	var buf bytes.Buffer
+	if err := animation.EncodeAPNG(&buf); err != nil {
+		t.Fatalf("failed to encode APNG: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode APNG: %v", err)
+	}
+	if c := color.NRGBAModel.Convert(img.At(1, 1)); c != (color.NRGBA{0xff, 0, 0, 0x80}) {
+		t.Errorf("unexpected pixel: %v", c)
+	}
+}
+
+func TestTrackPrefix(t *testing.T) {
+	positions := []s2.LatLng{s2.LatLngFromDegrees(0, 0), s2.LatLngFromDegrees(0, 1), s2.LatLngFromDegrees(0, 2)}
+	progress := []float64{0, 10, 20}
+
+	if prefix := trackPrefix(positions, progress, 0); len(prefix) != 1 {
+		t.Errorf("expected 1 position, got %d", len(prefix))
+	}
+	prefix := trackPrefix(positions, progress, 15)
+	if len(prefix) != 3 || prefix[2].Lng.Degrees() < 1.49 || prefix[2].Lng.Degrees() > 1.51 {
+		t.Errorf("unexpected prefix: %v", prefix)
+	}
+	if prefix := trackPrefix(positions, progress, 20); len(prefix) != 3 {
+		t.Errorf("expected 3 positions, got %d", len(prefix))
+	}
+}
+
+// pngChunk is a single chunk of a PNG file
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+// pngChunks splits an encoded PNG image into its chunks
+func pngChunks(data []byte) ([]pngChunk, error) {
+	const signatureSize = 8
+	if len(data) < signatureSize {
+		return nil, errors.New("invalid PNG data")
+	}
+	chunks := make([]pngChunk, 0)
+	for pos := signatureSize; pos < len(data); {
+		if pos+8 > len(data) {
+			return nil, errors.New("invalid PNG data")
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		if pos+12+length > len(data) {
+			return nil, errors.New("invalid PNG data")
+		}
+		chunks = append(chunks, pngChunk{string(data[pos+4 : pos+8]), data[pos+8 : pos+8+length]})
+		pos += 12 + length
+	}
+	return chunks, nil
+}
@@ -6,6 +6,7 @@
 package sm
 
 import (
+	"fmt"
 	"image/color"
 	"strconv"
 	"strings"
@@ -15,13 +16,16 @@ import (
 	"github.com/golang/geo/s2"
 )
 
-// Area represents a area or area on the map
+// Area represents a area or area on the map; Positions is the outer ring, Rings are additional rings, i.e. holes or
+// further parts, which are filled according to FillRule (the zero value gg.FillRuleWinding is the non-zero rule)
 type Area struct {
 	MapObject
 	Positions []s2.LatLng
+	Rings     [][]s2.LatLng
 	Color     color.Color
 	Fill      color.Color
 	Weight    float64
+	FillRule  gg.FillRule
 }
 
 // NewArea creates a new Area
@@ -35,15 +39,92 @@ func NewArea(positions []s2.LatLng, col color.Color, fill color.Color, weight fl
 	return a
 }
 
-// ParseAreaString parses a string and returns an area
+// newRingsArea creates a new Area from the explicitly closed rings of a polygon (e.g. from GeoJSON or WKT), i.e. the
+// outer ring followed by the holes; areas with holes use the even-odd fill rule, as the orientation of the rings is not
+// reliable in practice
+func newRingsArea(rings [][]s2.LatLng, col color.Color, fill color.Color, weight float64) *Area {
+	open := make([][]s2.LatLng, 0, len(rings))
+	for _, ring := range rings {
+		// areas are closed implicitly
+		if n := len(ring); n > 1 && ring[0] == ring[n-1] {
+			ring = ring[:n-1]
+		}
+		open = append(open, ring)
+	}
+	a := NewArea(open[0], col, fill, weight)
+	if len(open) > 1 {
+		a.Rings = open[1:]
+		a.FillRule = gg.FillRuleEvenOdd
+	}
+	return a
+}
+
+// AddPositions adds positions to the last ring, i.e. the outer ring if there are no additional rings
+func (p *Area) AddPositions(positions ...s2.LatLng) {
+	if len(p.Rings) > 0 {
+		p.Rings[len(p.Rings)-1] = append(p.Rings[len(p.Rings)-1], positions...)
+	} else {
+		p.Positions = append(p.Positions, positions...)
+	}
+}
+
+// parseFillRule parses "evenodd" or "nonzero"
+func parseFillRule(s string) (gg.FillRule, error) {
+	switch s {
+	case "evenodd":
+		return gg.FillRuleEvenOdd, nil
+	case "nonzero":
+		return gg.FillRuleWinding, nil
+	}
+	return gg.FillRuleWinding, fmt.Errorf("bad fill rule: '%s'", s)
+}
+
+// fillRuleString returns the name of the fill rule, as parsed by parseFillRule
+func fillRuleString(fillRule gg.FillRule) string {
+	if fillRule == gg.FillRuleEvenOdd {
+		return "evenodd"
+	}
+	return "nonzero"
+}
+
+// ParseAreaString parses a string and returns an area; strings yielding multiple areas (e.g. with 'geojson:') are rejected, see ParseAreasString
 func ParseAreaString(s string) (*Area, error) {
-	area := new(Area)
-	area.Color = color.RGBA{0xff, 0, 0, 0xff}
-	area.Fill = color.Transparent
-	area.Weight = 5.0
+	areas, err := ParseAreasString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(areas) != 1 {
+		return nil, fmt.Errorf("expected a single area, got %d areas: %s", len(areas), s)
+	}
+	return areas[0], nil
+}
+
+// ParseAreasString parses a string and returns an array of areas: the area of the given coordinates, and the polygons of 'geojson:', 'kml:', 'wkt:', and 'shp:' files;
+// the 'ring' token starts a new ring (a hole or a further part) of the area, whose rings are filled according to 'fillrule:' ('nonzero', the default, or 'evenodd')
+func ParseAreasString(s string) ([]*Area, error) {
+	areas := make([]*Area, 0)
+	area := NewArea(nil, color.RGBA{0xff, 0, 0, 0xff}, color.Transparent, 5.0)
+	hasFiles := false
 
-	for _, ss := range strings.Split(s, "|") {
-		if ok, suffix := hasPrefix(ss, "color:"); ok {
+	tokens := strings.Split(s, "|")
+	for i, ss := range tokens {
+		if isEnc, positions, err := parseEncodedPolylineToken(tokens[i:]); isEnc {
+			if err != nil {
+				return nil, err
+			}
+			area.AddPositions(positions...)
+			break
+		} else if ss == "ring" {
+			if len(area.Positions) > 0 {
+				area.Rings = append(area.Rings, nil)
+			}
+		} else if ok, suffix := hasPrefix(ss, "fillrule:"); ok {
+			var err error
+			area.FillRule, err = parseFillRule(suffix)
+			if err != nil {
+				return nil, err
+			}
+		} else if ok, suffix := hasPrefix(ss, "color:"); ok {
 			var err error
 			area.Color, err = ParseColorString(suffix)
 			if err != nil {
@@ -61,15 +142,45 @@ func ParseAreaString(s string) (*Area, error) {
 			if err != nil {
 				return nil, err
 			}
+		} else if isFile, fileAreas, err := parseAreaFileToken(ss, area.Color, area.Fill, area.Weight); isFile {
+			if err != nil {
+				return nil, err
+			}
+			areas = append(areas, fileAreas...)
+			hasFiles = true
 		} else {
 			lat, lng, err := coordsparser.Parse(ss)
 			if err != nil {
 				return nil, err
 			}
-			area.Positions = append(area.Positions, s2.LatLngFromDegrees(lat, lng))
+			area.AddPositions(s2.LatLngFromDegrees(lat, lng))
 		}
 	}
-	return area, nil
+	if len(area.Positions) > 0 || !hasFiles {
+		areas = append(areas, area)
+	}
+	return areas, nil
+}
+
+// parseAreaFileToken loads the areas of a 'geojson:', 'kml:', 'wkt:', or 'shp:' token
+func parseAreaFileToken(token string, col color.Color, fill color.Color, weight float64) (bool, []*Area, error) {
+	if ok, suffix := hasPrefix(token, "geojson:"); ok {
+		areas, err := loadGeoJSONAreas(suffix, col, fill, weight)
+		return true, areas, err
+	}
+	if ok, suffix := hasPrefix(token, "kml:"); ok {
+		areas, err := loadKMLAreas(suffix, col, fill, weight)
+		return true, areas, err
+	}
+	if ok, suffix := hasPrefix(token, "wkt:"); ok {
+		areas, err := loadWKTAreas(suffix, col, fill, weight)
+		return true, areas, err
+	}
+	if ok, suffix := hasPrefix(token, "shp:"); ok {
+		areas, err := loadShapefileAreas(suffix, col, fill, weight)
+		return true, areas, err
+	}
+	return false, nil, nil
 }
 
 // ExtraMarginPixels returns the left, top, right, bottom pixel margin of the Area object, which is exactly the line width.
@@ -83,11 +194,21 @@ func (p *Area) Bounds() s2.Rect {
 	for _, ll := range p.Positions {
 		r = r.AddPoint(ll)
 	}
+	for _, ring := range p.Rings {
+		for _, ll := range ring {
+			r = r.AddPoint(ll)
+		}
+	}
 	return r
 }
 
 // Draw draws the object in the given graphical context.
 func (p *Area) Draw(gc *gg.Context, trans *Transformer) {
+	p.DrawCanvas(gc, trans)
+}
+
+// DrawCanvas draws the object to the given canvas.
+func (p *Area) DrawCanvas(gc Canvas, trans *Transformer) {
 	if len(p.Positions) <= 1 {
 		return
 	}
@@ -96,12 +217,21 @@ func (p *Area) Draw(gc *gg.Context, trans *Transformer) {
 	gc.SetLineWidth(p.Weight)
 	gc.SetLineCap(gg.LineCapRound)
 	gc.SetLineJoin(gg.LineJoinRound)
-	for _, ll := range p.Positions {
-		gc.LineTo(trans.LatLngToXY(ll))
+	for _, ring := range append([][]s2.LatLng{p.Positions}, p.Rings...) {
+		if len(ring) <= 1 {
+			continue
+		}
+		gc.MoveTo(trans.LatLngToXY(ring[0]))
+		for _, ll := range ring[1:] {
+			gc.LineTo(trans.LatLngToXY(ll))
+		}
+		gc.ClosePath()
 	}
-	gc.ClosePath()
 	gc.SetColor(p.Fill)
+	gc.SetFillRule(p.FillRule)
 	gc.FillPreserve()
+	// restore gg's default for subsequent objects
+	gc.SetFillRule(gg.FillRuleWinding)
 	gc.SetColor(p.Color)
 	gc.Stroke()
 }
@@ -0,0 +1,69 @@
+package sm
+
+import (
+	"bytes"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/geo/s2"
+)
+
+func TestParseAreaStringRings(t *testing.T) {
+	area, err := ParseAreaString("fill:red|fillrule:evenodd|0,0|0,10|10,10|10,0|ring|2,2|2,8|8,8|8,2|ring|20,20|20,30|30,30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(area.Positions) != 4 || len(area.Rings) != 2 || len(area.Rings[0]) != 4 || len(area.Rings[1]) != 3 || area.FillRule != gg.FillRuleEvenOdd {
+		t.Errorf("unexpected area: %v", area)
+	}
+	if bounds := area.Bounds(); math.Abs(bounds.Hi().Lat.Degrees()-30) > 1e-9 {
+		t.Errorf("unexpected bounds: %v", bounds)
+	}
+
+	if area, err = ParseAreaString("0,0|0,10|10,10"); err != nil || area.FillRule != gg.FillRuleWinding {
+		t.Errorf("unexpected area: %v, %v", area, err)
+	}
+	if _, err := ParseAreaString("fillrule:odd|0,0|0,10|10,10"); err == nil {
+		t.Error("error expected for bad fill rule")
+	}
+}
+
+func TestRenderAreaHole(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetSize(200, 200)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.OverrideAttribution("")
+	outer := []s2.LatLng{s2.LatLngFromDegrees(-10, -10), s2.LatLngFromDegrees(10, -10), s2.LatLngFromDegrees(10, 10), s2.LatLngFromDegrees(-10, 10)}
+	area := NewArea(outer, color.Transparent, color.RGBA{0xff, 0, 0, 0xff}, 0)
+	if area.FillRule != (Area{}).FillRule {
+		t.Error("NewArea's fill rule differs from the zero value")
+	}
+	// the hole has the same orientation as the outer ring, i.e. it is only a hole with the even-odd rule
+	area.Rings = [][]s2.LatLng{{s2.LatLngFromDegrees(-5, -5), s2.LatLngFromDegrees(5, -5), s2.LatLngFromDegrees(5, 5), s2.LatLngFromDegrees(-5, 5)}}
+	area.FillRule = gg.FillRuleEvenOdd
+	ctx.AddObject(area)
+
+	img, trans, err := ctx.RenderWithOptions(RenderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y := trans.LatLngToXY(s2.LatLngFromDegrees(0, 0))
+	if _, _, _, a := img.At(int(x), int(y)).RGBA(); a != 0 {
+		t.Error("hole is filled")
+	}
+	x, y = trans.LatLngToXY(s2.LatLngFromDegrees(0, 8))
+	if r, _, _, _ := img.At(int(x), int(y)).RGBA(); r != 0xffff {
+		t.Error("ring is not filled")
+	}
+
+	var buf bytes.Buffer
+	if err := ctx.RenderSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `fill-rule="evenodd"`) {
+		t.Error("missing fill rule in SVG")
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// AttributionPosition specifies the position of the attribution box
+type AttributionPosition int
+
+const (
+	// AttributionPositionBottom places the attribution box across the full width at the bottom of the map
+	AttributionPositionBottom AttributionPosition = iota
+	// AttributionPositionTop places the attribution box across the full width at the top of the map
+	AttributionPositionTop
+	// AttributionPositionBottomLeft places a compact attribution box at the bottom left corner of the map
+	AttributionPositionBottomLeft
+	// AttributionPositionBottomRight places a compact attribution box at the bottom right corner of the map
+	AttributionPositionBottomRight
+	// AttributionPositionTopLeft places a compact attribution box at the top left corner of the map
+	AttributionPositionTopLeft
+	// AttributionPositionTopRight places a compact attribution box at the top right corner of the map
+	AttributionPositionTopRight
+)
+
+// AttributionStyle defines the appearance of the attribution box
+type AttributionStyle struct {
+	Position AttributionPosition
+	// Font is the font face used for the attribution text (e.g. loaded with gg.LoadFontFace); nil selects gg's default font
+	Font font.Face
+	// TextColor is the color of the attribution text; use the alpha channel for transparency
+	TextColor color.Color
+	// BoxColor is the background color of the attribution box; use the alpha channel for transparency
+	BoxColor color.Color
+	// Padding is the distance in pixels between the box border and its contents
+	Padding float64
+	// Spacing is the vertical distance in pixels between two lines of text
+	Spacing float64
+	// Margin is the distance in pixels between a compact box and the map borders
+	Margin float64
+	// Logo is an optional image (e.g. the tile provider's logo), which is drawn left of the text
+	Logo image.Image
+}
+
+// NewAttributionStyle creates the default AttributionStyle: white text in a semi-transparent black box across the full bottom of the map
+func NewAttributionStyle() *AttributionStyle {
+	s := new(AttributionStyle)
+	s.Position = AttributionPositionBottom
+	s.Font = nil
+	s.TextColor = color.NRGBA{0xff, 0xff, 0xff, 0xbf}
+	s.BoxColor = color.NRGBA{0x00, 0x00, 0x00, 0x7f}
+	s.Padding = 2.0
+	s.Spacing = 2.0
+	s.Margin = 0.0
+	s.Logo = nil
+	return s
+}
+
+// SetAttributionStyle sets the appearance of the attribution box; nil selects NewAttributionStyle
+func (m *Context) SetAttributionStyle(style *AttributionStyle) {
+	if style == nil {
+		style = NewAttributionStyle()
+	}
+	m.attributionStyle = style
+}
+
+// attributionLayout holds the measured dimensions of the attribution box
+type attributionLayout struct {
+	lines      []string
+	lineHeight float64
+	textWidth  float64
+	textHeight float64
+	logoWidth  float64
+	logoHeight float64
+	boxWidth   float64
+	boxHeight  float64
+}
+
+func (s *AttributionStyle) isFullWidth() bool {
+	return s.Position == AttributionPositionBottom || s.Position == AttributionPositionTop
+}
+
+func (s *AttributionStyle) isTop() bool {
+	return s.Position == AttributionPositionTop || s.Position == AttributionPositionTopLeft || s.Position == AttributionPositionTopRight
+}
+
+func (s *AttributionStyle) isRight() bool {
+	return s.Position == AttributionPositionBottomRight || s.Position == AttributionPositionTopRight
+}
+
+// fontFaceSetter is implemented by canvases that support custom font faces (e.g. gg.Context)
+type fontFaceSetter interface {
+	SetFontFace(fontFace font.Face)
+}
+
+// layout measures the attribution box for the given text
+func (s *AttributionStyle) layout(gc Canvas, attribution string) attributionLayout {
+	lines := strings.Split(attribution, "\n")
+	textWidth, lineHeight := s.measure(gc, lines)
+	return s.layoutLines(lines, textWidth, lineHeight)
+}
+
+// measure returns the width of the widest line and the height of the highest line
+func (s *AttributionStyle) measure(gc Canvas, lines []string) (float64, float64) {
+	if setter, ok := gc.(fontFaceSetter); ok && s.Font != nil {
+		setter.SetFontFace(s.Font)
+	}
+	textWidth, lineHeight := 0.0, 0.0
+	for _, line := range lines {
+		w, h := gc.MeasureString(line)
+		textWidth = math.Max(textWidth, w)
+		lineHeight = math.Max(lineHeight, h)
+	}
+	return textWidth, lineHeight
+}
+
+// layoutLines computes the dimensions of the attribution box for the measured lines
+func (s *AttributionStyle) layoutLines(lines []string, textWidth, lineHeight float64) attributionLayout {
+	l := attributionLayout{lines: lines, lineHeight: lineHeight, textWidth: textWidth}
+	l.textHeight = l.lineHeight*float64(len(l.lines)) + s.Spacing*float64(len(l.lines)-1)
+
+	contentWidth := l.textWidth
+	contentHeight := l.textHeight
+	if s.Logo != nil {
+		size := s.Logo.Bounds().Size()
+		l.logoWidth = float64(size.X)
+		l.logoHeight = float64(size.Y)
+		contentWidth += l.logoWidth + s.Padding
+		contentHeight = math.Max(contentHeight, l.logoHeight)
+	}
+	l.boxWidth = contentWidth + 2*s.Padding
+	l.boxHeight = contentHeight + 2*s.Padding
+	return l
+}
+
+// attributionTextSize caches the measured size of the attribution text, which does not change while zooming and centering
+type attributionTextSize struct {
+	attribution string
+	font        font.Face
+	textWidth   float64
+	lineHeight  float64
+}
+
+// attributionLayout returns the layout of the attribution box as measured by gg's raster canvas; the text is only
+// measured again if the attribution or the font has changed.
+func (m *Context) attributionLayout(attribution string) attributionLayout {
+	style := m.attributionStyle
+	lines := strings.Split(attribution, "\n")
+	cached := m.attributionText
+	if cached == nil || cached.attribution != attribution || cached.font != style.Font {
+		textWidth, lineHeight := style.measure(gg.NewContext(1, 1), lines)
+		cached = &attributionTextSize{attribution, style.Font, textWidth, lineHeight}
+		m.attributionText = cached
+	}
+	return style.layoutLines(lines, cached.textWidth, cached.lineHeight)
+}
+
+// attributionMarginPixels returns the heights of the map areas at the top and at the bottom that are covered by the attribution.
+func (m *Context) attributionMarginPixels() (float64, float64) {
+	attribution := m.Attribution()
+	if attribution == "" {
+		return 0.0, 0.0
+	}
+	style := m.attributionStyle
+	l := m.attributionLayout(attribution)
+	height := l.boxHeight
+	if !style.isFullWidth() {
+		height += style.Margin
+	}
+	if style.isTop() {
+		return height, 0.0
+	}
+	return 0.0, height
+}
+
+// drawAttribution draws the attribution box within the given image area
+func (m *Context) drawAttribution(gc Canvas, area image.Rectangle) {
+	attribution := m.Attribution()
+	if attribution == "" || area.Empty() {
+		return
+	}
+
+	style := m.attributionStyle
+	l := style.layout(gc, attribution)
+
+	// box position
+	x := float64(area.Min.X)
+	y := float64(area.Max.Y) - l.boxHeight
+	width := float64(area.Dx())
+	if style.isTop() {
+		y = float64(area.Min.Y)
+	}
+	if !style.isFullWidth() {
+		width = l.boxWidth
+		x += style.Margin
+		if style.isRight() {
+			x = float64(area.Max.X) - style.Margin - l.boxWidth
+		}
+		if style.isTop() {
+			y += style.Margin
+		} else {
+			y -= style.Margin
+		}
+	}
+
+	gc.SetColor(style.BoxColor)
+	gc.DrawRectangle(x, y, width, l.boxHeight)
+	gc.Fill()
+
+	x += style.Padding
+	contentHeight := l.boxHeight - 2*style.Padding
+	if style.Logo != nil {
+		gc.DrawImage(style.Logo, int(x), int(y+style.Padding+0.5*(contentHeight-l.logoHeight)))
+		x += l.logoWidth + style.Padding
+	}
+
+	gc.SetColor(style.TextColor)
+	y += style.Padding + 0.5*(contentHeight-l.textHeight)
+	for _, line := range l.lines {
+		gc.DrawStringAnchored(line, x, y, 0, 1)
+		y += style.Spacing + l.lineHeight
+	}
+}
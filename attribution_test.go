@@ -0,0 +1,127 @@
+package sm
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func newAttributionTestContext(style *AttributionStyle) *Context {
+	ctx := NewContext()
+	ctx.SetSize(100, 100)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.SetCenter(s2.LatLngFromDegrees(52.5, 13.4))
+	ctx.SetZoom(10)
+	ctx.OverrideAttribution("test")
+	ctx.SetAttributionStyle(style)
+	return ctx
+}
+
+func TestAttributionMarginPixels(t *testing.T) {
+	style := NewAttributionStyle()
+	ctx := newAttributionTestContext(style)
+	top, bottom := ctx.attributionMarginPixels()
+	if top != 0 || bottom <= 0 {
+		t.Fatalf("unexpected margins: %v, %v", top, bottom)
+	}
+	textHeight := bottom - 2*style.Padding
+
+	style.Padding = 10
+	if top, bottom := ctx.attributionMarginPixels(); top != 0 || bottom != textHeight+20 {
+		t.Errorf("unexpected margins with padding: %v, %v", top, bottom)
+	}
+	style.Position = AttributionPositionTop
+	if top, bottom := ctx.attributionMarginPixels(); top != textHeight+20 || bottom != 0 {
+		t.Errorf("unexpected margins at the top: %v, %v", top, bottom)
+	}
+	style.Position = AttributionPositionBottomRight
+	style.Margin = 5
+	if top, bottom := ctx.attributionMarginPixels(); top != 0 || bottom != textHeight+25 {
+		t.Errorf("unexpected margins of compact box: %v, %v", top, bottom)
+	}
+	style.Logo = image.NewRGBA(image.Rect(0, 0, 10, 100))
+	if top, bottom := ctx.attributionMarginPixels(); top != 0 || bottom != 125 {
+		t.Errorf("unexpected margins with logo: %v, %v", top, bottom)
+	}
+
+	ctx.OverrideAttribution("")
+	if top, bottom := ctx.attributionMarginPixels(); top != 0 || bottom != 0 {
+		t.Errorf("unexpected margins without attribution: %v, %v", top, bottom)
+	}
+}
+
+func TestRenderAttributionStyle(t *testing.T) {
+	blue := color.RGBA{0, 0, 0xff, 0xff}
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	logo := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	draw.Draw(logo, logo.Bounds(), image.NewUniform(red), image.Point{}, draw.Src)
+
+	style := NewAttributionStyle()
+	style.Position = AttributionPositionTop
+	style.BoxColor = blue
+	style.Padding = 10
+	style.Logo = logo
+	img, err := newAttributionTestContext(style).Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// box (with padding) at the top, followed by the logo; the rest of the map is empty
+	for _, test := range []struct {
+		x, y int
+		col  color.Color
+	}{
+		{1, 1, blue},
+		{99, 5, blue},
+		{5, 20, blue},
+		{20, 20, red},
+		{50, 45, color.RGBA{}},
+		{50, 99, color.RGBA{}},
+	} {
+		if c := color.RGBAModel.Convert(img.At(test.x, test.y)); c != test.col {
+			t.Errorf("unexpected color at %d,%d: %v", test.x, test.y, c)
+		}
+	}
+
+	style.Position = AttributionPositionBottomLeft
+	style.Margin = 4
+	if img, err = newAttributionTestContext(style).Render(); err != nil {
+		t.Fatal(err)
+	}
+	if c := color.RGBAModel.Convert(img.At(5, 94)); c != blue {
+		t.Errorf("unexpected color of compact box: %v", c)
+	}
+	if c := color.RGBAModel.Convert(img.At(2, 98)); c != (color.RGBA{}) {
+		t.Errorf("unexpected color of margin: %v", c)
+	}
+}
+
+func TestSetAttributionStyleNil(t *testing.T) {
+	ctx := newAttributionTestContext(nil)
+	if ctx.attributionStyle == nil {
+		t.Fatal("expected default attribution style")
+	}
+	if _, err := ctx.Render(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAttributionLayoutCache(t *testing.T) {
+	ctx := newAttributionTestContext(nil)
+	_, bottom := ctx.attributionMarginPixels()
+	cached := ctx.attributionText
+	if cached == nil || cached.attribution != "test" {
+		t.Fatalf("unexpected cached text size: %v", cached)
+	}
+	if _, b := ctx.attributionMarginPixels(); b != bottom || ctx.attributionText != cached {
+		t.Error("expected cached text size to be reused")
+	}
+
+	ctx.OverrideAttribution("test\ntest")
+	if _, b := ctx.attributionMarginPixels(); b <= bottom || ctx.attributionText == cached {
+		t.Errorf("expected text to be measured again: %v", b)
+	}
+}
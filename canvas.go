@@ -0,0 +1,106 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/fogleman/gg"
+)
+
+// Canvas is the drawing backend, map objects are drawn to.
+//
+// The methods are a subset of gg.Context's methods, i.e. *gg.Context is a Canvas producing raster images;
+// other implementations (e.g. the canvases of RenderSVG and RenderPDF) produce vector graphics.
+type Canvas interface {
+	// ClearPath clears the current path.
+	ClearPath()
+	// MoveTo starts a new subpath at the given point.
+	MoveTo(x, y float64)
+	// LineTo adds a line segment to the current path; it starts a new subpath if there is no current point.
+	LineTo(x, y float64)
+	// ClosePath closes the current subpath.
+	ClosePath()
+	// DrawArc adds a circular arc from angle1 to angle2 (radians, clockwise) to the current path.
+	DrawArc(x, y, r, angle1, angle2 float64)
+	// DrawRectangle adds a closed rectangle subpath to the current path.
+	DrawRectangle(x, y, w, h float64)
+
+	// SetLineWidth sets the line width for stroking.
+	SetLineWidth(lineWidth float64)
+	// SetLineCap sets the line cap style for stroking.
+	SetLineCap(lineCap gg.LineCap)
+	// SetLineJoin sets the line join style for stroking.
+	SetLineJoin(lineJoin gg.LineJoin)
+	// SetColor sets the color for filling, stroking, and text.
+	SetColor(c color.Color)
+	// SetFillRule sets the fill rule, which determines the inside of self-intersecting paths and paths with multiple subpaths.
+	SetFillRule(fillRule gg.FillRule)
+
+	// Fill fills the current path and clears it afterwards.
+	Fill()
+	// FillPreserve fills the current path without clearing it.
+	FillPreserve()
+	// Stroke strokes the current path and clears it afterwards.
+	Stroke()
+
+	// DrawImage draws the image with its top left corner at the given point.
+	DrawImage(im image.Image, x, y int)
+	// DrawStringAnchored draws the text at the given point; ax, ay specify the relative anchor position within the text's bounding box.
+	DrawStringAnchored(s string, x, y, ax, ay float64)
+	// MeasureString returns the width and height of the rendered text.
+	MeasureString(s string) (w, h float64)
+}
+
+// make sure that gg.Context satisfies Canvas
+var _ Canvas = (*gg.Context)(nil)
+
+// drawToCanvas draws the complete cropped map to a (vector) canvas: the background and the tile layers as a single image,
+// map objects implementing CanvasObject and the attribution as vector graphics, and all other map objects as images.
+func (m *Context) drawToCanvas(c Canvas, zoom int, trans *Transformer) error {
+	bounds := image.Rect(0, 0, trans.pWidth, trans.pHeight)
+	base := image.NewRGBA(bounds)
+	if err := m.renderBase(base, zoom, trans); err != nil {
+		return err
+	}
+	if !isEmptyImage(base) {
+		c.DrawImage(base, 0, 0)
+	}
+
+	var raster *image.RGBA
+	for _, object := range m.objects {
+		if o, ok := object.(CanvasObject); ok {
+			if raster != nil {
+				c.DrawImage(raster, 0, 0)
+				raster = nil
+			}
+			o.DrawCanvas(c, trans)
+			continue
+		}
+		// consecutive objects without vector support are drawn to a shared raster image
+		if raster == nil {
+			raster = image.NewRGBA(bounds)
+		}
+		object.Draw(gg.NewContextForRGBA(raster), trans)
+	}
+	if raster != nil {
+		c.DrawImage(raster, 0, 0)
+	}
+
+	m.drawAttribution(c, bounds)
+	return nil
+}
+
+// isEmptyImage checks if all pixels of img are fully transparent
+func isEmptyImage(img *image.RGBA) bool {
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
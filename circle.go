@@ -10,7 +10,6 @@ import (
 
 	"github.com/flopp/go-coordsparser"
 	"github.com/fogleman/gg"
-	"github.com/golang/geo/s1"
 	"github.com/golang/geo/s2"
 )
 
@@ -75,24 +74,9 @@ func ParseCircleString(s string) (circles []*Circle, err error) {
 	return circles, nil
 }
 
-func (m *Circle) getLatLng(plus bool) s2.LatLng {
-	const (
-		R = 6371000.0
-	)
-	th := m.Radius / R
-	br := 0 / float64(s1.Degree)
-	if !plus {
-		th *= -1
-	}
-	lat := m.Position.Lat.Radians()
-	lat1 := math.Asin(math.Sin(lat)*math.Cos(th) + math.Cos(lat)*math.Sin(th)*math.Cos(br))
-	lng1 := m.Position.Lng.Radians() +
-		math.Atan2(math.Sin(br)*math.Sin(th)*math.Cos(lat),
-			math.Cos(th)-math.Sin(lat)*math.Sin(lat1))
-	return s2.LatLng{
-		Lat: s1.Angle(lat1),
-		Lng: s1.Angle(lng1),
-	}
+// Ring returns the densified polygon of points with the distance Radius around the center.
+func (m *Circle) Ring() []s2.LatLng {
+	return geodesicRing(m.Position, 0, 2*math.Pi, func(float64) float64 { return m.Radius })
 }
 
 // ExtraMarginPixels returns the left, top, right, bottom pixel margin of the Circle object, which is exactly the line width.
@@ -102,28 +86,29 @@ func (m *Circle) ExtraMarginPixels() (float64, float64, float64, float64) {
 
 // Bounds returns the geographical boundary rect (excluding the actual pixel dimensions).
 func (m *Circle) Bounds() s2.Rect {
-	r := s2.EmptyRect()
-	r = r.AddPoint(m.getLatLng(false))
-	r = r.AddPoint(m.getLatLng(true))
-	return r
+	if m.Radius <= 0 {
+		return s2.RectFromLatLng(m.Position)
+	}
+	return ringBounds(m.Ring())
 }
 
 // Draw draws the object in the given graphical context.
 func (m *Circle) Draw(gc *gg.Context, trans *Transformer) {
+	m.DrawCanvas(gc, trans)
+}
+
+// DrawCanvas draws the object to the given canvas.
+func (m *Circle) DrawCanvas(gc Canvas, trans *Transformer) {
 	if !CanDisplay(m.Position) {
 		log.Printf("Circle coordinates not displayable: %f/%f", m.Position.Lat.Degrees(), m.Position.Lng.Degrees())
 		return
 	}
 
-	ll := m.getLatLng(true)
-	x, y := trans.LatLngToXY(m.Position)
-	x1, y1 := trans.LatLngToXY(ll)
-	radius := math.Sqrt(math.Pow(x1-x, 2) + math.Pow(y1-y, 2))
 	gc.ClearPath()
 	gc.SetLineWidth(m.Weight)
 	gc.SetLineCap(gg.LineCapRound)
 	gc.SetLineJoin(gg.LineJoinRound)
-	gc.DrawCircle(x, y, radius)
+	drawRing(gc, trans, m.Position, m.Ring())
 	gc.SetColor(m.Fill)
 	gc.FillPreserve()
 	gc.SetColor(m.Color)
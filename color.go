@@ -7,12 +7,17 @@ package sm
 
 import (
 	"image/color"
+	"math"
+	"strings"
 
 	"github.com/mazznoer/csscolorparser"
 )
 
-// ParseColorString parses hex color strings (i.e. `#RRGGBB`, `RRGGBBAA`, `#RRGGBBAA`), and named colors (e.g. 'black', 'blue', ...)
+// ParseColorString parses hex color strings (i.e. `#RRGGBB`, `RRGGBBAA`, `#RRGGBBAA`, and Google Static Maps style `0xRRGGBB`, `0xRRGGBBAA`), and named colors (e.g. 'black', 'blue', ...)
 func ParseColorString(s string) (color.Color, error) {
+	if ok, suffix := hasPrefix(strings.ToLower(s), "0x"); ok {
+		s = "#" + suffix
+	}
 	col, err := csscolorparser.Parse(s)
 	if err != nil {
 		return nil, err
@@ -27,3 +32,25 @@ func Luminance(col color.Color) float64 {
 	r, g, b, _ := col.RGBA()
 	return (float64(r)*0.299 + float64(g)*0.587 + float64(b)*0.114) / float64(0xffff)
 }
+
+// InterpolateColor linearly interpolates between the colors from (t=0.0) and to (t=1.0); t is clamped to [0.0, 1.0].
+func InterpolateColor(from, to color.Color, t float64) color.Color {
+	t = math.Max(0.0, math.Min(1.0, t))
+	r1, g1, b1, a1 := from.RGBA()
+	r2, g2, b2, a2 := to.RGBA()
+	lerp := func(v1, v2 uint32) uint16 {
+		return uint16(float64(v1) + t*(float64(v2)-float64(v1)) + 0.5)
+	}
+	return color.RGBA64{lerp(r1, r2), lerp(g1, g2), lerp(b1, b2), lerp(a1, a2)}
+}
+
+// withOpacity returns the color with the given opacity
+func withOpacity(col color.Color, opacity float64) color.Color {
+	n := color.NRGBAModel.Convert(col).(color.NRGBA)
+	if c, isRGBA := col.(color.RGBA); isRGBA {
+		// colors returned by ParseColorString are not premultiplied
+		n = color.NRGBA{c.R, c.G, c.B, c.A}
+	}
+	n.A = uint8(0xff*opacity + 0.5)
+	return n
+}
@@ -25,6 +25,8 @@ func TestParseColor(t *testing.T) {
 		{"ff00ff42", color.RGBA{0xFF, 0x00, 0xFF, 0x42}, false},
 		{"ff00ff", color.RGBA{0xFF, 0x00, 0xFF, 0xFF}, false},
 		{"f0f", color.RGBA{0xFF, 0x00, 0xFF, 0xFF}, false},
+		{"0xFF00FF", color.RGBA{0xFF, 0x00, 0xFF, 0xFF}, false},
+		{"0xff00ff42", color.RGBA{0xFF, 0x00, 0xFF, 0x42}, false},
 		{"bad-name", color.RGBA{0x00, 0x00, 0x00, 0x00}, true},
 		{"#FF00F", color.RGBA{0x00, 0x00, 0x00, 0x00}, true},
 		{"#GGGGGG", color.RGBA{0x00, 0x00, 0x00, 0x00}, true},
@@ -8,12 +8,12 @@ package sm
 
 import (
 	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
 	"log"
 	"math"
-	"strings"
 	"sync"
 
 	"github.com/fogleman/gg"
@@ -29,8 +29,14 @@ type Context struct {
 
 	hasZoom bool
 	zoom    int
+	minZoom int
 	maxZoom int
 
+	paddingLeft   float64
+	paddingTop    float64
+	paddingRight  float64
+	paddingBottom float64
+
 	hasCenter bool
 	center    s2.LatLng
 
@@ -46,8 +52,11 @@ type Context struct {
 	online       bool
 	tileProvider *TileProvider
 	cache        TileCache
+	memoryCache  *MemoryTileCache
 
 	overrideAttribution *string
+	attributionStyle    *AttributionStyle
+	attributionText     *attributionTextSize
 }
 
 // NewContext creates a new instance of Context
@@ -56,6 +65,7 @@ func NewContext() *Context {
 	t.width = 512
 	t.height = 512
 	t.hasZoom = false
+	t.minZoom = 0
 	t.maxZoom = 30
 	t.hasCenter = false
 	t.hasBoundingBox = false
@@ -64,6 +74,7 @@ func NewContext() *Context {
 	t.online = true
 	t.tileProvider = NewTileProviderOpenStreetMaps()
 	t.cache = NewTileCacheFromUserCache(0777)
+	t.attributionStyle = NewAttributionStyle()
 	return t
 }
 
@@ -77,6 +88,11 @@ func (m *Context) SetCache(cache TileCache) {
 	m.cache = cache
 }
 
+// SetMemoryCache sets a cache of decoded tile images, which may be shared by multiple Contexts; nil disables it
+func (m *Context) SetMemoryCache(cache *MemoryTileCache) {
+	m.memoryCache = cache
+}
+
 // SetOnline enables/disables online
 // TileFetcher will only fetch tiles from cache if online = false
 func (m *Context) SetOnline(online bool) {
@@ -100,11 +116,26 @@ func (m *Context) SetZoom(zoom int) {
 	m.hasZoom = true
 }
 
+// SetMinZoom sets the lower zoom level limit when using dynamic zoom
+func (m *Context) SetMinZoom(n int) {
+	m.minZoom = n
+}
+
 // SetMaxZoom sets the upper zoom level limit when using dynamic zoom
 func (m *Context) SetMaxZoom(n int) {
 	m.maxZoom = n
 }
 
+// SetPadding sets the left, top, right, bottom padding in pixels, i.e. the image borders that are kept free of map objects (e.g. because they are covered by UI overlays).
+//
+// The padding is respected when determining the zoom level, and the map center (whether given or determined automatically) is placed at the center of the padded area.
+func (m *Context) SetPadding(left, top, right, bottom float64) {
+	m.paddingLeft = left
+	m.paddingTop = top
+	m.paddingRight = right
+	m.paddingBottom = bottom
+}
+
 // SetCenter sets the center coordinates
 func (m *Context) SetCenter(center s2.LatLng) {
 	m.center = center
@@ -255,12 +286,8 @@ func (m *Context) determineBounds() s2.Rect {
 
 func (m *Context) determineExtraMarginPixels() (float64, float64, float64, float64) {
 	maxL := 0.0
-	maxT := 0.0
 	maxR := 0.0
-	maxB := 0.0
-	if m.Attribution() != "" {
-		maxB = 12.0
-	}
+	maxT, maxB := m.attributionMarginPixels()
 	for _, object := range m.objects {
 		l, t, r, b := object.ExtraMarginPixels()
 		maxL = math.Max(maxL, l)
@@ -268,13 +295,24 @@ func (m *Context) determineExtraMarginPixels() (float64, float64, float64, float
 		maxR = math.Max(maxR, r)
 		maxB = math.Max(maxB, b)
 	}
-	return maxL, maxT, maxR, maxB
+	return maxL + m.paddingLeft, maxT + m.paddingTop, maxR + m.paddingRight, maxB + m.paddingBottom
+}
+
+// clampZoom restricts an automatically determined zoom level to the range [minZoom, maxZoom]
+func (m *Context) clampZoom(zoom int) int {
+	if zoom > m.maxZoom {
+		zoom = m.maxZoom
+	}
+	if zoom < m.minZoom {
+		zoom = m.minZoom
+	}
+	return zoom
 }
 
 func (m *Context) determineZoom(bounds s2.Rect, center s2.LatLng) int {
 	b := bounds.AddPoint(center)
 	if b.IsEmpty() || b.IsPoint() {
-		return 15
+		return m.clampZoom(15)
 	}
 
 	tileSize := m.tileProvider.TileSize
@@ -304,33 +342,40 @@ func (m *Context) determineZoom(bounds s2.Rect, center s2.LatLng) int {
 	for zoom < m.maxZoom {
 		tiles := float64(uint(1) << uint(zoom))
 		if dx*tiles > w || dy*tiles > h {
-			return zoom - 1
+			return m.clampZoom(zoom - 1)
 		}
 		zoom = zoom + 1
 	}
 
-	return m.maxZoom
+	return m.clampZoom(m.maxZoom)
 }
 
-// determineCenter computes a point that is visually centered in Mercator projection
-func (m *Context) determineCenter(bounds s2.Rect) s2.LatLng {
-	latLo := bounds.Lo().Lat.Radians()
-	latHi := bounds.Hi().Lat.Radians()
-	yLo := math.Log((1+math.Sin(latLo))/(1-math.Sin(latLo))) / 2
-	yHi := math.Log((1+math.Sin(latHi))/(1-math.Sin(latHi))) / 2
-	lat := s1.Angle(math.Atan(math.Sinh((yLo + yHi) / 2)))
-	lng := bounds.Center().Lng
-	return s2.LatLng{Lat: lat, Lng: lng}
-}
-
-// adjustCenter adjust the center such that the map objects are properly centerd in the view wrt. their pixel margins.
-func (m *Context) adjustCenter(center s2.LatLng, zoom int) s2.LatLng {
+// determineZoomForCenter computes the largest zoom level (within [minZoom, maxZoom]), such that all map objects fit into the padded image area, if the map is centered at 'center'.
+func (m *Context) determineZoomForCenter(center s2.LatLng) int {
 	if len(m.objects) == 0 {
-		return center
+		return m.clampZoom(15)
 	}
 
-	transformer := newTransformer(m.width, m.height, zoom, center, m.tileProvider.TileSize)
+	zoom := m.minZoom
+	for zoom < m.maxZoom && m.objectsFit(center, zoom+1) {
+		zoom = zoom + 1
+	}
+	return zoom
+}
+
+// objectsFit checks if all map objects (including their pixel margins) are within the padded image area, if the map is centered at 'center' using the zoom level 'zoom'.
+func (m *Context) objectsFit(center s2.LatLng, zoom int) bool {
+	transformer := newTransformer(m.width, m.height, zoom, m.applyPadding(center, zoom), m.tileProvider.TileSize)
+	minX, minY, maxX, maxY := m.objectsPixelExtent(transformer)
+	originX := float64(transformer.pCenterX - m.width/2)
+	originY := float64(transformer.pCenterY - m.height/2)
+	attributionTop, attributionBottom := m.attributionMarginPixels()
+	return minX-originX >= m.paddingLeft && maxX-originX <= float64(m.width)-m.paddingRight &&
+		minY-originY >= m.paddingTop+attributionTop && maxY-originY <= float64(m.height)-m.paddingBottom-attributionBottom
+}
 
+// objectsPixelExtent computes the pixel extent of all map objects including their pixel margins.
+func (m *Context) objectsPixelExtent(transformer *Transformer) (float64, float64, float64, float64) {
 	first := true
 	minX := 0.0
 	maxX := 0.0
@@ -354,14 +399,47 @@ func (m *Context) adjustCenter(center s2.LatLng, zoom int) s2.LatLng {
 			maxY = math.Max(maxY, seY+b)
 		}
 	}
+	return minX, minY, maxX, maxY
+}
 
-	if (maxX-minX) > float64(m.width) || (maxY-minY) > float64(m.height) {
-		log.Printf("Object margins are bigger than the target image size => ignoring object margins for adjusting the center")
+// applyPadding shifts the center, such that the original center is displayed at the center of the padded image area.
+func (m *Context) applyPadding(center s2.LatLng, zoom int) s2.LatLng {
+	if m.paddingLeft == m.paddingRight && m.paddingTop == m.paddingBottom {
 		return center
 	}
 
-	centerX := (maxX + minX) * 0.5
-	centerY := (maxY + minY) * 0.5
+	transformer := newTransformer(m.width, m.height, zoom, center, m.tileProvider.TileSize)
+	x, y := transformer.LatLngToXY(center)
+	return transformer.XYToLatLng(x-0.5*(m.paddingLeft-m.paddingRight), y-0.5*(m.paddingTop-m.paddingBottom))
+}
+
+// determineCenter computes a point that is visually centered in Mercator projection
+func (m *Context) determineCenter(bounds s2.Rect) s2.LatLng {
+	latLo := bounds.Lo().Lat.Radians()
+	latHi := bounds.Hi().Lat.Radians()
+	yLo := math.Log((1+math.Sin(latLo))/(1-math.Sin(latLo))) / 2
+	yHi := math.Log((1+math.Sin(latHi))/(1-math.Sin(latHi))) / 2
+	lat := s1.Angle(math.Atan(math.Sinh((yLo + yHi) / 2)))
+	lng := bounds.Center().Lng
+	return s2.LatLng{Lat: lat, Lng: lng}
+}
+
+// adjustCenter adjust the center such that the map objects are properly centerd in the padded view wrt. their pixel margins.
+func (m *Context) adjustCenter(center s2.LatLng, zoom int) s2.LatLng {
+	if len(m.objects) == 0 {
+		return m.applyPadding(center, zoom)
+	}
+
+	transformer := newTransformer(m.width, m.height, zoom, center, m.tileProvider.TileSize)
+	minX, minY, maxX, maxY := m.objectsPixelExtent(transformer)
+
+	if (maxX-minX) > float64(m.width)-m.paddingLeft-m.paddingRight || (maxY-minY) > float64(m.height)-m.paddingTop-m.paddingBottom {
+		log.Printf("Object margins are bigger than the target image size => ignoring object margins for adjusting the center")
+		return m.applyPadding(center, zoom)
+	}
+
+	centerX := (maxX+minX)*0.5 - 0.5*(m.paddingLeft-m.paddingRight)
+	centerY := (maxY+minY)*0.5 - 0.5*(m.paddingTop-m.paddingBottom)
 
 	return transformer.XYToLatLng(centerX, centerY)
 }
@@ -369,14 +447,16 @@ func (m *Context) adjustCenter(center s2.LatLng, zoom int) s2.LatLng {
 func (m *Context) determineZoomCenter() (int, s2.LatLng, error) {
 	if m.hasBoundingBox && !m.boundingBox.IsEmpty() {
 		center := m.determineCenter(m.boundingBox)
-		return m.determineZoom(m.boundingBox, center), center, nil
+		zoom := m.determineZoom(m.boundingBox, center)
+		return zoom, m.applyPadding(center, zoom), nil
 	}
 
 	if m.hasCenter {
-		if m.hasZoom {
-			return m.zoom, m.center, nil
+		zoom := m.zoom
+		if !m.hasZoom {
+			zoom = m.determineZoomForCenter(m.center)
 		}
-		return m.determineZoom(m.determineBounds(), m.center), m.center, nil
+		return zoom, m.applyPadding(m.center, zoom), nil
 	}
 
 	bounds := m.determineBounds()
@@ -403,6 +483,7 @@ type Transformer struct {
 	tCenterX, tCenterY float64 // tile index to requested center
 	tOriginX, tOriginY int     // bottom left tile to download
 	pMinX, pMaxX       int
+	pOffsetX, pOffsetY int // pixel location of the image's top left corner in the set of tiles
 	proj               s2.Projection
 }
 
@@ -453,6 +534,20 @@ func newTransformer(width int, height int, zoom int, llCenter s2.LatLng, tileSiz
 	return t
 }
 
+// window returns a copy of the Transformer, whose pixel coordinates refer to the image window of size width x height with the top left corner at x, y.
+func (t *Transformer) window(x, y, width, height int) *Transformer {
+	w := *t
+	w.pOffsetX += x
+	w.pOffsetY += y
+	w.pCenterX -= x
+	w.pCenterY -= y
+	w.pMinX -= x
+	w.pMaxX -= x
+	w.pWidth = width
+	w.pHeight = height
+	return &w
+}
+
 // ll2t returns fractional tile index for a lat/lng points
 func (t *Transformer) ll2t(ll s2.LatLng) (float64, float64) {
 	p := t.proj.FromLatLng(ll)
@@ -485,106 +580,168 @@ func (t *Transformer) XYToLatLng(x float64, y float64) s2.LatLng {
 	return t.proj.ToLatLng(r2.Point{X: xx, Y: yy})
 }
 
-// Rect returns an s2.Rect bounding box around the set of tiles described by Transformer.
+// Rect returns an s2.Rect bounding box around the image described by Transformer, i.e. the set of tiles or the cropped image.
 func (t *Transformer) Rect() (bbox s2.Rect) {
 	// transform from https://wiki.openstreetmap.org/wiki/Slippy_map_tilenames#Go
 	invNumTiles := 1.0 / t.numTiles
+	tileSize := float64(t.tileSize)
+	minX := float64(t.tOriginX) + float64(t.pOffsetX)/tileSize
+	maxX := minX + float64(t.pWidth)/tileSize
+	minY := float64(t.tOriginY) + float64(t.pOffsetY)/tileSize
+	maxY := minY + float64(t.pHeight)/tileSize
 	// Get latitude bounds
-	n := math.Pi - 2.0*math.Pi*float64(t.tOriginY)*invNumTiles
+	n := math.Pi - 2.0*math.Pi*minY*invNumTiles
 	bbox.Lat.Hi = math.Atan(0.5 * (math.Exp(n) - math.Exp(-n)))
-	n = math.Pi - 2.0*math.Pi*float64(t.tOriginY+t.tCountY)*invNumTiles
+	n = math.Pi - 2.0*math.Pi*maxY*invNumTiles
 	bbox.Lat.Lo = math.Atan(0.5 * (math.Exp(n) - math.Exp(-n)))
 	// Get longtitude bounds, much easier
-	bbox.Lng.Lo = float64(t.tOriginX)*invNumTiles*2.0*math.Pi - math.Pi
-	bbox.Lng.Hi = float64(t.tOriginX+t.tCountX)*invNumTiles*2.0*math.Pi - math.Pi
+	bbox.Lng.Lo = minX*invNumTiles*2.0*math.Pi - math.Pi
+	bbox.Lng.Hi = maxX*invNumTiles*2.0*math.Pi - math.Pi
 	return bbox
 }
 
+// webMercatorRadius is the sphere radius of the web mercator projection (EPSG:3857) in meters
+const webMercatorRadius = 6378137.0
+
+// XYToWebMercator transforms image x, y coordinates to web mercator (EPSG:3857) coordinates in meters; (0, 0) is the top left corner of the image's top left pixel.
+func (t *Transformer) XYToWebMercator(x float64, y float64) (float64, float64) {
+	tileSize := float64(t.tileSize)
+	tx := float64(t.tOriginX) + (float64(t.pOffsetX)+x)/tileSize
+	ty := float64(t.tOriginY) + (float64(t.pOffsetY)+y)/tileSize
+	return (tx/t.numTiles - 0.5) * 2.0 * math.Pi * webMercatorRadius, (0.5 - ty/t.numTiles) * 2.0 * math.Pi * webMercatorRadius
+}
+
+// PixelSize returns the width (and height) of an image pixel in web mercator (EPSG:3857) meters.
+func (t *Transformer) PixelSize() float64 {
+	return 2.0 * math.Pi * webMercatorRadius / (t.numTiles * float64(t.tileSize))
+}
+
+// AttributionPlacement specifies where the attribution is drawn
+type AttributionPlacement int
+
+const (
+	// AttributionMap draws the attribution within the requested map area
+	AttributionMap AttributionPlacement = iota
+	// AttributionImage draws the attribution within the returned image; this only differs from AttributionMap for uncropped images
+	AttributionImage
+	// AttributionNone disables the attribution
+	AttributionNone
+)
+
+// RenderOptions controls the output of RenderWithOptions
+type RenderOptions struct {
+	// Uncropped disables cropping, i.e. the returned image covers the requested map area as well as any tiles necessary to cover that area
+	Uncropped bool
+	// Attribution specifies where the attribution is drawn
+	Attribution AttributionPlacement
+}
+
 // Render actually renders the map image including all map objects (markers, paths, areas)
 func (m *Context) Render() (image.Image, error) {
+	img, _, err := m.RenderWithOptions(RenderOptions{})
+	return img, err
+}
+
+// RenderWithOptions actually renders the map image including all map objects (markers, paths, areas) as specified by opts.
+//
+// The returned Transformer maps geographical coordinates to pixel coordinates of the returned image.
+func (m *Context) RenderWithOptions(opts RenderOptions) (image.Image, *Transformer, error) {
 	zoom, center, err := m.determineZoomCenter()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	tileSize := m.tileProvider.TileSize
-	trans := newTransformer(m.width, m.height, zoom, center, tileSize)
-	img := image.NewRGBA(image.Rect(0, 0, trans.pWidth, trans.pHeight))
-	gc := gg.NewContextForRGBA(img)
-	if m.background != nil {
-		draw.Draw(img, img.Bounds(), &image.Uniform{m.background}, image.Point{}, draw.Src)
+	trans := newTransformer(m.width, m.height, zoom, center, m.tileProvider.TileSize)
+	viewport := image.Rect(0, 0, m.width, m.height).Add(image.Pt(trans.pMinX, trans.pCenterY-m.height/2))
+	if !opts.Uncropped {
+		trans = trans.window(viewport.Min.X, viewport.Min.Y, m.width, m.height)
+		viewport = viewport.Sub(viewport.Min)
 	}
 
-	// fetch and draw tiles to img
-	layers := []*TileProvider{m.tileProvider}
-	if m.overlays != nil {
-		layers = append(layers, m.overlays...)
+	img := image.NewRGBA(image.Rect(0, 0, trans.pWidth, trans.pHeight))
+	if err := m.renderTo(img, zoom, trans, m.attributionArea(opts.Attribution, viewport, img.Bounds())); err != nil {
+		return nil, nil, err
 	}
+	return img, trans, nil
+}
 
-	for _, layer := range layers {
-		if err := m.renderLayer(gc, zoom, trans, tileSize, layer); err != nil {
-			return nil, err
-		}
+// attributionArea returns the image area, within which the attribution is drawn; the area is empty, if no attribution should be drawn
+func (m *Context) attributionArea(placement AttributionPlacement, viewport image.Rectangle, bounds image.Rectangle) image.Rectangle {
+	switch placement {
+	case AttributionMap:
+		return viewport
+	case AttributionImage:
+		return bounds
+	case AttributionNone:
+		return image.Rectangle{}
 	}
+	return viewport
+}
 
-	// draw map objects
-	for _, object := range m.objects {
-		object.Draw(gc, trans)
+// RenderInto renders the map image including all map objects (markers, paths, areas) directly into the rectangle r of dst; the map is drawn on top of the existing contents of dst.
+//
+// The size of the rendered map is determined by r, i.e. the size set with SetSize is ignored.
+// Rendering is most efficient for *image.RGBA targets, as no intermediate image is allocated.
+func (m *Context) RenderInto(dst draw.Image, r image.Rectangle) error {
+	if r.Empty() || !r.In(dst.Bounds()) {
+		return fmt.Errorf("invalid target rectangle %v for image bounds %v", r, dst.Bounds())
 	}
 
-	// crop image
-	croppedImg := image.NewRGBA(image.Rect(0, 0, int(m.width), int(m.height)))
-	draw.Draw(croppedImg, image.Rect(0, 0, int(m.width), int(m.height)),
-		img, image.Point{trans.pCenterX - int(m.width)/2, trans.pCenterY - int(m.height)/2},
-		draw.Src)
-
-	// draw attribution
-	attribution := m.Attribution()
-	if attribution == "" {
-		return croppedImg, nil
-	}
-	lines := strings.Split(attribution, "\n")
-	lineHeight := 0.0
-	for _, line := range lines {
-		_, h := gc.MeasureString(line)
-		if h > float64(lineHeight) {
-			lineHeight = h
-		}
+	// work on a shallow copy, such that the target size does not leak into m
+	c := *m
+	c.SetSize(r.Dx(), r.Dy())
+	zoom, trans, err := c.croppedTransformer()
+	if err != nil {
+		return err
 	}
-	margin := 2.0
-	spacing := 2.0
-	boxHeight := lineHeight*float64(len(lines)) + 2*margin + spacing*float64(len(lines)-1)
-	gc = gg.NewContextForRGBA(croppedImg)
-	gc.SetRGBA(0.0, 0.0, 0.0, 0.5)
-	gc.DrawRectangle(0.0, float64(m.height)-boxHeight, float64(m.width), boxHeight)
-	gc.Fill()
-	gc.SetRGBA(1.0, 1.0, 1.0, 0.75)
-	y := float64(m.height) - boxHeight
-	for _, line := range lines {
-		gc.DrawStringAnchored(line, margin, y, 0, 1)
-		y += spacing + lineHeight
+	area := image.Rect(0, 0, r.Dx(), r.Dy())
+
+	if rgba, ok := dst.(*image.RGBA); ok {
+		// re-use the pixels of the target rectangle, but with (0, 0) as top left corner
+		sub := rgba.SubImage(r).(*image.RGBA)
+		img := &image.RGBA{Pix: sub.Pix, Stride: sub.Stride, Rect: area}
+		return c.renderTo(img, zoom, trans, area)
 	}
 
-	return croppedImg, nil
+	img := image.NewRGBA(area)
+	if err := c.renderTo(img, zoom, trans, area); err != nil {
+		return err
+	}
+	draw.Draw(dst, r, img, image.Point{}, draw.Over)
+	return nil
 }
 
-// RenderWithTransformer actually renders the map image including all map objects (markers, paths, areas).
-// The returned image covers requested area as well as any tiles necessary to cover that area, which may
-// be larger than the request.
-//
-// A Transformer is returned to support image registration with other data.
-func (m *Context) RenderWithTransformer() (image.Image, *Transformer, error) {
+// croppedTransformer determines zoom level and center, and returns a Transformer for the cropped map image
+func (m *Context) croppedTransformer() (int, *Transformer, error) {
 	zoom, center, err := m.determineZoomCenter()
 	if err != nil {
-		return nil, nil, err
+		return 0, nil, err
 	}
+	trans := newTransformer(m.width, m.height, zoom, center, m.tileProvider.TileSize)
+	return zoom, trans.window(trans.pMinX, trans.pCenterY-m.height/2, m.width, m.height), nil
+}
 
-	tileSize := m.tileProvider.TileSize
-	trans := newTransformer(m.width, m.height, zoom, center, tileSize)
-	img := image.NewRGBA(image.Rect(0, 0, trans.pWidth, trans.pHeight))
+// renderTo draws the background, the tile layers, all map objects, and the attribution (within attributionArea) to img.
+func (m *Context) renderTo(img *image.RGBA, zoom int, trans *Transformer, attributionArea image.Rectangle) error {
+	if err := m.renderBase(img, zoom, trans); err != nil {
+		return err
+	}
+
+	// draw map objects
+	gc := gg.NewContextForRGBA(img)
+	for _, object := range m.objects {
+		object.Draw(gc, trans)
+	}
+
+	m.drawAttribution(gc, attributionArea)
+	return nil
+}
+
+// renderBase draws the background and the tile layers to img.
+func (m *Context) renderBase(img *image.RGBA, zoom int, trans *Transformer) error {
 	gc := gg.NewContextForRGBA(img)
 	if m.background != nil {
-		draw.Draw(img, img.Bounds(), &image.Uniform{m.background}, image.Point{}, draw.Src)
+		draw.Draw(img, img.Bounds(), &image.Uniform{m.background}, image.Point{}, draw.Over)
 	}
 
 	// fetch and draw tiles to img
@@ -594,29 +751,21 @@ func (m *Context) RenderWithTransformer() (image.Image, *Transformer, error) {
 	}
 
 	for _, layer := range layers {
-		if err := m.renderLayer(gc, zoom, trans, tileSize, layer); err != nil {
-			return nil, nil, err
+		if err := m.renderLayer(gc, zoom, trans, m.tileProvider.TileSize, layer); err != nil {
+			return err
 		}
 	}
 
-	// draw map objects
-	for _, object := range m.objects {
-		object.Draw(gc, trans)
-	}
-
-	// draw attribution
-	if m.tileProvider.Attribution == "" {
-		return img, trans, nil
-	}
-	_, textHeight := gc.MeasureString(m.tileProvider.Attribution)
-	boxHeight := textHeight + 4.0
-	gc.SetRGBA(0.0, 0.0, 0.0, 0.5)
-	gc.DrawRectangle(0.0, float64(trans.pHeight)-boxHeight, float64(trans.pWidth), boxHeight)
-	gc.Fill()
-	gc.SetRGBA(1.0, 1.0, 1.0, 0.75)
-	gc.DrawString(m.tileProvider.Attribution, 4.0, float64(m.height)-4.0)
+	return nil
+}
 
-	return img, trans, nil
+// RenderWithTransformer actually renders the map image including all map objects (markers, paths, areas).
+// The returned image covers requested area as well as any tiles necessary to cover that area, which may
+// be larger than the request.
+//
+// A Transformer is returned to support image registration with other data.
+func (m *Context) RenderWithTransformer() (image.Image, *Transformer, error) {
+	return m.RenderWithOptions(RenderOptions{Uncropped: true})
 }
 
 // RenderWithBounds actually renders the map image including all map objects (markers, paths, areas).
@@ -642,12 +791,19 @@ func (m *Context) renderLayer(gc *gg.Context, zoom int, trans *Transformer, tile
 	tiles := (1 << uint(zoom))
 	fetchedTiles := make(chan *Tile)
 	t := NewTileFetcher(provider, m.cache, m.online)
+	t.SetMemoryCache(m.memoryCache)
 	if m.userAgent != "" {
 		t.SetUserAgent(m.userAgent)
 	}
 
+	// tiles (relative to the tile origin) that intersect the image window
+	minXX := floorDiv(trans.pOffsetX, tileSize)
+	maxXX := floorDiv(trans.pOffsetX+trans.pWidth-1, tileSize)
+	minYY := floorDiv(trans.pOffsetY, tileSize)
+	maxYY := floorDiv(trans.pOffsetY+trans.pHeight-1, tileSize)
+
 	go func() {
-		for xx := 0; xx < trans.tCountX; xx++ {
+		for xx := minXX; xx <= maxXX; xx++ {
 			x := trans.tOriginX + xx
 			if x < 0 {
 				x = x + tiles
@@ -658,7 +814,7 @@ func (m *Context) renderLayer(gc *gg.Context, zoom int, trans *Transformer, tile
 				log.Printf("Skipping out of bounds tile column %d/?", x)
 				continue
 			}
-			for yy := 0; yy < trans.tCountY; yy++ {
+			for yy := minYY; yy <= maxYY; yy++ {
 				y := trans.tOriginY + yy
 				if y < 0 || y >= tiles {
 					log.Printf("Skipping out of bounds tile %d/%d", x, y)
@@ -669,8 +825,8 @@ func (m *Context) renderLayer(gc *gg.Context, zoom int, trans *Transformer, tile
 				go func(wg *sync.WaitGroup, tile *Tile, xx, yy int) {
 					defer wg.Done()
 					if err := t.Fetch(tile); err == nil {
-						tile.X = xx * tileSize
-						tile.Y = yy * tileSize
+						tile.X = xx*tileSize - trans.pOffsetX
+						tile.Y = yy*tileSize - trans.pOffsetY
 						fetchedTiles <- tile
 					} else if err == errTileNotFound && provider.IgnoreNotFound {
 						log.Printf("Error downloading tile file: %s (Ignored)", err)
@@ -1,7 +1,9 @@
 package sm
 
 import (
+	"image"
 	"image/color"
+	"image/draw"
 	"testing"
 
 	"github.com/golang/geo/s2"
@@ -50,3 +52,178 @@ func TestRenderEverything(t *testing.T) {
 		t.Errorf("unexpected image size: %d x %d; expected %d x %d", img.Bounds().Dx(), img.Bounds().Dy(), width, height)
 	}
 }
+
+// pixelInImage transforms ll to cropped image coordinates
+func pixelInImage(ctx *Context, ll s2.LatLng) (float64, float64, error) {
+	trans, err := ctx.Transformer()
+	if err != nil {
+		return 0, 0, err
+	}
+	x, y := trans.LatLngToXY(ll)
+	return x - float64(trans.pCenterX-ctx.width/2), y - float64(trans.pCenterY-ctx.height/2), nil
+}
+
+func TestFitObjectsAroundCenter(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetSize(400, 300)
+	ctx.SetTileProvider(NewTileProviderNone())
+	center := s2.LatLngFromDegrees(52.5, 13.4)
+	far := s2.LatLngFromDegrees(52.9, 14.4)
+	ctx.SetCenter(center)
+	ctx.AddObject(NewMarker(far, color.RGBA{255, 0, 0, 255}, 16.0))
+
+	x, y, err := pixelInImage(ctx, center)
+	if err != nil {
+		t.Fatalf("failed to create transformer: %v", err)
+	}
+	if x != 200 || y != 150 {
+		t.Errorf("center is not at the image center: %f/%f", x, y)
+	}
+
+	x, y, _ = pixelInImage(ctx, far)
+	if x < 0 || x > 400 || y < 0 || y > 300 {
+		t.Errorf("marker is outside of the image: %f/%f", x, y)
+	}
+}
+
+func TestPadding(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetSize(400, 300)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.SetPadding(150, 50, 0, 0)
+	ctx.SetMinZoom(3)
+	coords := []s2.LatLng{
+		s2.LatLngFromDegrees(52.5, 13.4),
+		s2.LatLngFromDegrees(52.9, 14.4),
+		s2.LatLngFromDegrees(52.1, 12.4),
+	}
+	for _, ll := range coords {
+		ctx.AddObject(NewMarker(ll, color.RGBA{255, 0, 0, 255}, 16.0))
+	}
+
+	for _, ll := range coords {
+		x, y, err := pixelInImage(ctx, ll)
+		if err != nil {
+			t.Fatalf("failed to create transformer: %v", err)
+		}
+		if x < 150 || x > 400 || y < 50 || y > 300 {
+			t.Errorf("marker is outside of the padded area: %f/%f", x, y)
+		}
+	}
+
+	zoom, _, _ := ctx.determineZoomCenter()
+	if zoom < 3 {
+		t.Errorf("zoom level below minimum: %d", zoom)
+	}
+}
+
+func TestRenderInto(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetSize(200, 100)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.SetBackground(color.RGBA{255, 255, 255, 255})
+	ctx.OverrideAttribution("attribution")
+	ctx.AddObject(NewMarker(s2.LatLngFromDegrees(48.123, 7.0), color.RGBA{255, 0, 0, 255}, 16.0))
+	ctx.AddObject(NewMarker(s2.LatLngFromDegrees(48.987, 8.0), color.RGBA{0, 0, 255, 255}, 16.0))
+
+	expected, err := ctx.Render()
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	offset := image.Pt(30, 20)
+	r := image.Rectangle{offset, offset.Add(image.Pt(200, 100))}
+	for _, dst := range []draw.Image{image.NewRGBA(image.Rect(0, 0, 300, 200)), image.NewNRGBA(image.Rect(0, 0, 300, 200))} {
+		if err := ctx.RenderInto(dst, r); err != nil {
+			t.Fatalf("failed to render into %T: %v", dst, err)
+		}
+		for y := 0; y < 100; y++ {
+			for x := 0; x < 200; x++ {
+				if !sameColor(expected.At(x, y), dst.At(x+offset.X, y+offset.Y)) {
+					t.Fatalf("%T: unexpected color at %d/%d", dst, x, y)
+				}
+			}
+		}
+		if _, _, _, a := dst.At(0, 0).RGBA(); a != 0 {
+			t.Errorf("%T: pixels outside of the target rectangle have been modified", dst)
+		}
+	}
+
+	if err := ctx.RenderInto(image.NewRGBA(image.Rect(0, 0, 100, 100)), r); err == nil {
+		t.Errorf("expected error for target rectangle exceeding the image bounds")
+	}
+}
+
+// sameColor compares two colors, allowing for small differences caused by anti-aliasing at different pixel offsets
+func sameColor(c1, c2 color.Color) bool {
+	r1, g1, b1, a1 := c1.RGBA()
+	r2, g2, b2, a2 := c2.RGBA()
+	near := func(v1, v2 uint32) bool {
+		return v1>>8 <= (v2>>8)+2 && v2>>8 <= (v1>>8)+2
+	}
+	return near(r1, r2) && near(g1, g2) && near(b1, b2) && near(a1, a2)
+}
+
+func TestRenderUncroppedMatchesRender(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetSize(300, 200)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.SetBackground(color.RGBA{255, 255, 255, 255})
+	ctx.OverrideAttribution("first line\nsecond line")
+	ctx.AddObject(NewMarker(s2.LatLngFromDegrees(48.123, 7.0), color.RGBA{255, 0, 0, 255}, 16.0))
+	ctx.AddObject(NewPath([]s2.LatLng{s2.LatLngFromDegrees(48.123, 7.0), s2.LatLngFromDegrees(48.987, 8.0)}, color.RGBA{0, 0, 255, 255}, 4.0))
+	ctx.AddObject(NewCircle(s2.LatLngFromDegrees(48.5, 7.5), color.RGBA{0, 255, 0, 255}, color.RGBA{0, 255, 0, 100}, 10000.0, 2.0))
+
+	cropped, croppedTrans, err := ctx.RenderWithOptions(RenderOptions{})
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	uncropped, trans, err := ctx.RenderWithTransformer()
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	ll := s2.LatLngFromDegrees(48.123, 7.0)
+	x0, y0 := croppedTrans.LatLngToXY(ll)
+	x1, y1 := trans.LatLngToXY(ll)
+	dx := int(x1 - x0)
+	dy := int(y1 - y0)
+	if float64(dx) != x1-x0 || float64(dy) != y1-y0 {
+		t.Fatalf("non-integer offset between cropped and uncropped image: %f/%f", x1-x0, y1-y0)
+	}
+
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 300; x++ {
+			if !sameColor(cropped.At(x, y), uncropped.At(x+dx, y+dy)) {
+				t.Fatalf("cropped and uncropped images differ at %d/%d: %v %v", x, y, cropped.At(x, y), uncropped.At(x+dx, y+dy))
+			}
+		}
+	}
+}
+
+func TestRenderAttributionNone(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetSize(300, 200)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.SetCenter(s2.LatLngFromDegrees(48.0, 7.0))
+	ctx.SetZoom(10)
+	ctx.SetBackground(color.RGBA{255, 255, 255, 255})
+	ctx.OverrideAttribution("attribution")
+
+	img, _, err := ctx.RenderWithOptions(RenderOptions{Attribution: AttributionNone})
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if !sameColor(img.At(5, 195), color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("unexpected attribution box")
+	}
+
+	img, _, err = ctx.RenderWithOptions(RenderOptions{Uncropped: true, Attribution: AttributionImage})
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	b := img.Bounds()
+	if sameColor(img.At(b.Min.X+1, b.Max.Y-1), color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("missing attribution box at the bottom of the uncropped image")
+	}
+}
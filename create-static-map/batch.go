@@ -0,0 +1,341 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sm "github.com/flopp/go-staticmaps"
+)
+
+// batchItem is a single map of a batch manifest
+type batchItem struct {
+	line   int
+	output string
+	spec   *sm.MapSpec
+	// markers, paths, areas, and circles are given in the command line syntax
+	markers string
+	paths   string
+	areas   string
+	circles string
+	// err is set if the manifest entry is invalid
+	err error
+}
+
+// jsonBatchItem is a JSON lines manifest entry: a map spec with an additional "output" field
+type jsonBatchItem struct {
+	Output string `json:"output"`
+	sm.MapSpec
+}
+
+// batchSettings are the settings shared by all maps of a batch
+type batchSettings struct {
+	jobs                int
+	userAgent           string
+	thunderforestAPIKey string
+	memoryCache         *sm.MemoryTileCache
+	output              outputOptions
+}
+
+type batchResult struct {
+	item     batchItem
+	err      error
+	duration time.Duration
+}
+
+// runBatch renders the maps of the manifest using settings.jobs parallel workers, and reports the result of each map to
+// stdout; it fails if the settings are invalid, if the manifest cannot be read, or if any map fails.
+func runBatch(manifest string, settings batchSettings) error {
+	if settings.jobs < 1 {
+		return fmt.Errorf("bad number of jobs: %d", settings.jobs)
+	}
+	// shared output options are checked upfront, such that they do not fail each map individually
+	if err := settings.output.validate(); err != nil {
+		return err
+	}
+	items, err := loadBatchManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	queue := make(chan batchItem)
+	results := make(chan batchResult)
+	var wg sync.WaitGroup
+	for i := 0; i < settings.jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range queue {
+				start := time.Now()
+				err := renderBatchItem(item, settings)
+				results <- batchResult{item, err, time.Since(start)}
+			}
+		}()
+	}
+	go func() {
+		for _, item := range items {
+			queue <- item
+		}
+		close(queue)
+		wg.Wait()
+		close(results)
+	}()
+
+	failed := 0
+	for result := range results {
+		if result.err != nil {
+			failed++
+			fmt.Printf("FAIL\t%d\t%s\t%v\n", result.item.line, result.item.output, result.err)
+		} else {
+			fmt.Printf("OK\t%d\t%s\t%v\n", result.item.line, result.item.output, result.duration.Round(time.Millisecond))
+		}
+	}
+	log.Printf("%d maps succeeded, %d maps failed", len(items)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d maps failed", failed, len(items))
+	}
+	return nil
+}
+
+func renderBatchItem(item batchItem, settings batchSettings) error {
+	if item.err != nil {
+		return item.err
+	}
+	// templates are shared by multiple items, so the spec is copied before adding the API key
+	spec := *item.spec
+	if spec.Provider != nil && spec.Provider.APIKey == "" {
+		provider := *spec.Provider
+		provider.APIKey = settings.thunderforestAPIKey
+		spec.Provider = &provider
+	}
+	ctx, err := sm.NewContextFromSpec(&spec)
+	if err != nil {
+		return err
+	}
+	ctx.SetMemoryCache(settings.memoryCache)
+	if settings.userAgent != "" {
+		ctx.SetUserAgent(settings.userAgent)
+	}
+	if err := addBatchObjects(ctx, item); err != nil {
+		return err
+	}
+
+	output := settings.output
+	output.fileName = item.output
+	return saveOutput(ctx, output)
+}
+
+func addBatchObjects(ctx *sm.Context, item batchItem) error {
+	if item.markers != "" {
+		markers, err := sm.ParseMarkerString(item.markers)
+		if err != nil {
+			return err
+		}
+		for _, marker := range markers {
+			ctx.AddObject(marker)
+		}
+	}
+	if item.paths != "" {
+		paths, err := sm.ParsePathString(item.paths)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			ctx.AddObject(path)
+		}
+	}
+	if item.areas != "" {
+		areas, err := sm.ParseAreasString(item.areas)
+		if err != nil {
+			return err
+		}
+		for _, area := range areas {
+			ctx.AddObject(area)
+		}
+	}
+	if item.circles != "" {
+		circles, err := sm.ParseCircleString(item.circles)
+		if err != nil {
+			return err
+		}
+		for _, circle := range circles {
+			ctx.AddObject(circle)
+		}
+	}
+	return nil
+}
+
+// loadBatchManifest reads a CSV (".csv") or JSON lines (".jsonl", ".ndjson", ".json") manifest; invalid entries are
+// returned as items with an error, such that they are reported along with the rendered maps.
+func loadBatchManifest(fileName string) ([]batchItem, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return readCSVManifest(file)
+	case ".jsonl", ".ndjson", ".json":
+		return readJSONManifest(file)
+	}
+	return nil, fmt.Errorf("unknown manifest format: '%s'", fileName)
+}
+
+// readJSONManifest reads a manifest with one JSON map spec (with an additional "output" field) per line
+func readJSONManifest(r io.Reader) ([]batchItem, error) {
+	items := make([]batchItem, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var entry jsonBatchItem
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		item := batchItem{line: line}
+		if err := decoder.Decode(&entry); err != nil {
+			item.err = err
+		} else if entry.Output == "" {
+			item.err = errors.New("missing output file name")
+		} else {
+			item.output = entry.Output
+			item.spec = &entry.MapSpec
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+// readCSVManifest reads a CSV manifest; the header row names the columns, i.e. "output" (required), "spec" (a map spec
+// file, which serves as template), "width", "height", "provider", "center", "zoom", "bbox", "marker", "path", "area",
+// and "circle" (the latter four using the command line syntax)
+func readCSVManifest(r io.Reader) ([]batchItem, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "output", "spec", "width", "height", "provider", "center", "zoom", "bbox", "marker", "path", "area", "circle":
+			columns[name] = i
+		default:
+			return nil, fmt.Errorf("unknown manifest column: '%s'", name)
+		}
+	}
+	if _, ok := columns["output"]; !ok {
+		return nil, errors.New("missing manifest column: 'output'")
+	}
+
+	templates := make(map[string]*sm.MapSpec)
+	items := make([]batchItem, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var item batchItem
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			item.line = parseErr.StartLine
+			item.err = err
+		} else {
+			item.line, _ = reader.FieldPos(0)
+			value := func(name string) string {
+				if i, ok := columns[name]; ok {
+					return strings.TrimSpace(record[i])
+				}
+				return ""
+			}
+			item.err = fillCSVBatchItem(&item, value, templates)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func fillCSVBatchItem(item *batchItem, value func(name string) string, templates map[string]*sm.MapSpec) error {
+	item.output = value("output")
+	if item.output == "" {
+		return errors.New("missing output file name")
+	}
+
+	spec := new(sm.MapSpec)
+	if fileName := value("spec"); fileName != "" {
+		template, ok := templates[fileName]
+		if !ok {
+			var err error
+			if template, err = sm.LoadMapSpec(fileName); err != nil {
+				return err
+			}
+			templates[fileName] = template
+		}
+		*spec = *template
+	}
+	item.spec = spec
+
+	if err := setCSVInt(value("width"), &spec.Width); err != nil {
+		return err
+	}
+	if err := setCSVInt(value("height"), &spec.Height); err != nil {
+		return err
+	}
+	if s := value("zoom"); s != "" {
+		zoom, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		spec.Zoom = &zoom
+	}
+	if s := value("provider"); s != "" {
+		spec.Provider = &sm.TileProviderSpec{Name: s}
+	}
+	if s := value("center"); s != "" {
+		spec.Center = s
+	}
+	if s := value("bbox"); s != "" {
+		spec.BBox = strings.Split(s, "|")
+	}
+	item.markers = value("marker")
+	item.paths = value("path")
+	item.areas = value("area")
+	item.circles = value("circle")
+	return nil
+}
+
+func setCSVInt(s string, value *int) error {
+	if s == "" {
+		return nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*value = v
+	return nil
+}
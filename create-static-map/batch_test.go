@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadCSVManifest(t *testing.T) {
+	specFileName := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specFileName, []byte(`{"width": 300, "height": 200, "provider": "osm"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		manifest string
+		// errs are the expected item errors (empty for valid items); nil expects an error for the whole manifest
+		errs []string
+	}{
+		{"unknown column", "output,color\na.png,red\n", nil},
+		{"missing output column", "width,height\n100,100\n", nil},
+		{"valid", "output,width,height,zoom,center\na.png,100,50,3,52.5;13.4\n", []string{""}},
+		{"missing output", "output,width\na.png,100\n,100\n", []string{"", "missing output file name"}},
+		{"bad width", "output,width\na.png,x\nb.png,100\n", []string{"invalid syntax", ""}},
+		{"bad field count", "output,width\na.png,100,3\nb.png,100\n", []string{"wrong number of fields", ""}},
+		{"spec template", "output,spec,width\na.png," + specFileName + ",\nb.png," + specFileName + ",400\n", []string{"", ""}},
+		{"missing spec", "output,spec\na.png,missing.json\n", []string{"missing.json"}},
+	} {
+		items, err := readCSVManifest(strings.NewReader(test.manifest))
+		if test.errs == nil {
+			if err == nil {
+				t.Errorf("%s: error expected", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		checkBatchItems(t, test.name, items, test.errs)
+	}
+}
+
+func TestReadCSVManifestValues(t *testing.T) {
+	specFileName := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(specFileName, []byte(`{"width": 300, "height": 200, "provider": "osm"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	items, err := readCSVManifest(strings.NewReader("output,spec,width,marker\na.png," + specFileName + ",,52.5;13.4\nb.png," + specFileName + ",400,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].line != 2 || items[1].line != 3 {
+		t.Fatalf("unexpected items: %v", items)
+	}
+	if spec := items[0].spec; spec.Width != 300 || spec.Height != 200 || spec.Provider == nil || items[0].markers != "52.5;13.4" {
+		t.Errorf("unexpected template spec: %v", spec)
+	}
+	// the template is copied, i.e. not modified by other items
+	if items[1].spec.Width != 400 || items[0].spec.Width != 300 {
+		t.Errorf("unexpected widths: %d, %d", items[0].spec.Width, items[1].spec.Width)
+	}
+}
+
+func TestReadJSONManifest(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		manifest string
+		errs     []string
+	}{
+		{"valid", `{"output": "a.png", "width": 100, "height": 100}` + "\n\n" + `{"output": "b.png"}`, []string{"", ""}},
+		{"missing output", `{"width": 100}` + "\n" + `{"output": "b.png"}`, []string{"missing output file name", ""}},
+		{"unknown field", `{"output": "a.png", "colour": "red"}`, []string{"unknown field"}},
+		{"malformed line", `{"output": "a.png"` + "\n" + `{"output": "b.png"}`, []string{"unexpected EOF", ""}},
+	} {
+		items, err := readJSONManifest(strings.NewReader(test.manifest))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		checkBatchItems(t, test.name, items, test.errs)
+	}
+}
+
+func checkBatchItems(t *testing.T, name string, items []batchItem, errs []string) {
+	t.Helper()
+	if len(items) != len(errs) {
+		t.Errorf("%s: expected %d items, got %d", name, len(errs), len(items))
+		return
+	}
+	for i, item := range items {
+		switch {
+		case errs[i] == "" && item.err != nil:
+			t.Errorf("%s: unexpected error of item %d: %v", name, i, item.err)
+		case errs[i] == "" && (item.spec == nil || item.output == ""):
+			t.Errorf("%s: incomplete item %d: %v", name, i, item)
+		case errs[i] != "" && (item.err == nil || !strings.Contains(item.err.Error(), errs[i])):
+			t.Errorf("%s: expected error '%s' for item %d, got %v", name, errs[i], i, item.err)
+		}
+	}
+}
+
+func TestRunBatchSettings(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "batch.jsonl")
+	if err := os.WriteFile(manifest, []byte(`{"output": "a.png"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runBatch(manifest, batchSettings{jobs: 0, output: outputOptions{compression: "default"}}); err == nil {
+		t.Error("error expected for 0 jobs")
+	}
+	if err := runBatch(manifest, batchSettings{jobs: 1, output: outputOptions{format: "gif", compression: "default"}}); err == nil {
+		t.Error("error expected for bad format")
+	}
+	if err := runBatch(manifest, batchSettings{jobs: 1, output: outputOptions{compression: "tiny"}}); err == nil {
+		t.Error("error expected for bad compression")
+	}
+}
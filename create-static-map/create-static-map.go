@@ -6,15 +6,17 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"image/png"
 	"log"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/flopp/go-coordsparser"
 	sm "github.com/flopp/go-staticmaps"
-	"github.com/fogleman/gg"
 	"github.com/golang/geo/s2"
 	"github.com/jessevdk/go-flags"
 )
@@ -131,11 +133,13 @@ func handlePathsOption(ctx *sm.Context, parameters []string) {
 
 func handleAreasOption(ctx *sm.Context, parameters []string) {
 	for _, s := range parameters {
-		area, err := sm.ParseAreaString(s)
+		areas, err := sm.ParseAreasString(s)
 		if err != nil {
 			log.Fatal(err)
 		} else {
-			ctx.AddObject(area)
+			for _, area := range areas {
+				ctx.AddObject(area)
+			}
 		}
 	}
 }
@@ -153,6 +157,235 @@ func handleCirclesOption(ctx *sm.Context, parameters []string) {
 	}
 }
 
+func handleCSVOption(ctx *sm.Context, parameters []string) {
+	for _, s := range parameters {
+		objects, err := sm.ParseCSVString(s)
+		if err != nil {
+			log.Fatal(err)
+		} else {
+			for _, object := range objects {
+				ctx.AddObject(object)
+			}
+		}
+	}
+}
+
+func handleSpecOption(fileName string, thunderforestAPIKey string) *sm.Context {
+	spec, err := sm.LoadMapSpec(fileName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if spec.Provider != nil && spec.Provider.APIKey == "" {
+		spec.Provider.APIKey = thunderforestAPIKey
+	}
+	ctx, err := sm.NewContextFromSpec(spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return ctx
+}
+
+func saveSpec(ctx *sm.Context, fileName string) error {
+	spec, err := ctx.Spec()
+	if err != nil {
+		return err
+	}
+	return spec.Save(fileName)
+}
+
+func saveSVG(ctx *sm.Context, fileName string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := ctx.RenderSVG(file); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func savePDF(ctx *sm.Context, fileName string, dpi float64, paper string) error {
+	opts := sm.PDFOptions{DPI: dpi}
+	if paper != "" {
+		w, h, err := sm.PaperSize(paper)
+		if err != nil {
+			return err
+		}
+		opts.PageWidth = w
+		opts.PageHeight = h
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := ctx.RenderPDF(file, opts); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func saveGeoTIFF(ctx *sm.Context, fileName string, strips int) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if strips > 0 {
+		err = ctx.RenderGeoTIFFStrips(file, strips)
+	} else {
+		err = ctx.RenderGeoTIFF(file)
+	}
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func savePNGStrips(ctx *sm.Context, fileName string, strips int) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := ctx.RenderPNGStrips(file, strips); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func saveImage(ctx *sm.Context, fileName string, opts sm.EncodeOptions, georef bool) error {
+	img, trans, err := ctx.RenderWithOptions(sm.RenderOptions{})
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := sm.EncodeImage(file, img, opts); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if georef {
+		return sm.SaveGeoreference(fileName, trans)
+	}
+	return nil
+}
+
+func parseCompressionLevel(s string) (png.CompressionLevel, error) {
+	switch strings.ToLower(s) {
+	case "default":
+		return png.DefaultCompression, nil
+	case "none":
+		return png.NoCompression, nil
+	case "fast":
+		return png.BestSpeed, nil
+	case "best":
+		return png.BestCompression, nil
+	}
+	return png.DefaultCompression, fmt.Errorf("unknown compression level: '%s'", s)
+}
+
+func handleImageOptions(fileName, format, compression string, quality, colors int) (sm.EncodeOptions, error) {
+	var err error
+	opts := sm.EncodeOptions{Quality: quality, Colors: colors}
+	if format == "" {
+		if opts.Format, err = sm.ImageFormatFromFileName(fileName); err != nil {
+			return opts, err
+		}
+	} else if opts.Format, err = sm.ParseImageFormat(format); err != nil {
+		return opts, err
+	}
+	if opts.CompressionLevel, err = parseCompressionLevel(compression); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+type outputOptions struct {
+	fileName    string
+	format      string
+	compression string
+	quality     int
+	colors      int
+	georef      bool
+	strips      int
+	dpi         float64
+	paper       string
+}
+
+// validate checks the options that do not depend on the output file name, i.e. the format and the compression level
+func (opts outputOptions) validate() error {
+	switch strings.ToLower(opts.format) {
+	case "", "svg", "pdf", "tif", "tiff":
+	default:
+		if _, err := sm.ParseImageFormat(opts.format); err != nil {
+			return err
+		}
+	}
+	_, err := parseCompressionLevel(opts.compression)
+	return err
+}
+
+func saveOutput(ctx *sm.Context, opts outputOptions) error {
+	format := strings.ToLower(opts.format)
+	if format == "" {
+		format = strings.ToLower(strings.TrimPrefix(filepath.Ext(opts.fileName), "."))
+	}
+
+	switch format {
+	case "svg":
+		return saveSVG(ctx, opts.fileName)
+	case "pdf":
+		return savePDF(ctx, opts.fileName, opts.dpi, opts.paper)
+	case "tif", "tiff":
+		return saveGeoTIFF(ctx, opts.fileName, opts.strips)
+	}
+
+	encodeOptions, err := handleImageOptions(opts.fileName, opts.format, opts.compression, opts.quality, opts.colors)
+	if err != nil {
+		return err
+	}
+	if opts.strips > 0 {
+		if encodeOptions.Format != sm.FormatPNG || opts.georef {
+			return errors.New("--strips is only supported for PNG (without --georef) and TIFF output")
+		}
+		return savePNGStrips(ctx, opts.fileName, opts.strips)
+	}
+	return saveImage(ctx, opts.fileName, encodeOptions, opts.georef)
+}
+
+// createContext creates the Context from the map spec file (if '--spec' is set), and applies the map type and size options
+func createContext(parser *flags.Parser, specFileName string, mapType string, thunderforestAPIKey string, width int, height int) *sm.Context {
+	ctx := sm.NewContext()
+	hasSpec := parser.FindOptionByLongName("spec").IsSet()
+	if hasSpec {
+		ctx = handleSpecOption(specFileName, thunderforestAPIKey)
+	}
+
+	if parser.FindOptionByLongName("type").IsSet() {
+		handleTypeOption(ctx, mapType, thunderforestAPIKey)
+	}
+
+	if !hasSpec || parser.FindOptionByLongName("width").IsSet() || parser.FindOptionByLongName("height").IsSet() {
+		ctx.SetSize(width, height)
+	}
+	return ctx
+}
+
 func main() {
 	var opts struct {
 		//		ClearCache bool     `long:"clear-cache" description:"Clears the tile cache"`
@@ -170,8 +403,25 @@ func main() {
 		Paths              []string `short:"p" long:"path" description:"Add a path to the static map" value-name:"PATH"`
 		Areas              []string `short:"a" long:"area" description:"Add an area to the static map" value-name:"AREA"`
 		Circles            []string `short:"C" long:"circle" description:"Add a circle to the static map" value-name:"CIRCLE"`
+		CSV                []string `long:"csv" description:"Add markers or circles from the rows of a CSV file" value-name:"CSV"`
 		ThunderforstAPIKey string   `long:"thunderforestapikey" description:"API key to use with Thunderforst tile servers" value-name:"APIKEY" default:"NONE"`
 		Attribution        string   `long:"attribution" description:"Override the attribution text" value-name:"ATTRIBUTION"`
+		Format             string   `long:"format" description:"Output format (png, png8, jpeg, svg, pdf, tiff); determined by the output file name if not specified" value-name:"FORMAT"`
+		Compression        string   `long:"compression" description:"Compression level of PNG output (default, none, fast, best)" value-name:"LEVEL" default:"default"`
+		Quality            int      `long:"quality" description:"Quality of JPEG output (1-100)" value-name:"QUALITY" default:"90"`
+		Colors             int      `long:"colors" description:"Maximum number of colors of paletted PNG output (2-256)" value-name:"COLORS" default:"256"`
+		Georef             bool     `long:"georef" description:"Write a world file and a .aux.xml file with georeferencing information next to PNG or JPEG output"`
+		Strips             int      `long:"strips" description:"Render PNG or TIFF output in strips of the given height to reduce the memory usage for huge images" value-name:"ROWS"`
+		DPI                float64  `long:"dpi" description:"Resolution of PDF output" value-name:"DPI" default:"72"`
+		Paper              string   `long:"paper" description:"Page size of PDF output, e.g. 'A4' or 'letter-landscape'; overrides --width and --height" value-name:"PAPER"`
+		Spec               string   `long:"spec" description:"Load the map from a JSON or YAML map spec file; further options (e.g. --marker) are applied on top" value-name:"FILENAME"`
+		SaveSpec           string   `long:"save-spec" description:"Save the map as JSON or YAML map spec file instead of rendering it" value-name:"FILENAME"`
+		Batch              string   `long:"batch" description:"Render all maps of a CSV or JSON lines manifest file" value-name:"MANIFEST"`
+		Jobs               int      `long:"jobs" description:"Number of maps rendered in parallel in batch mode" value-name:"N" default:"4"`
+		TileMemory         int      `long:"tile-memory" description:"Number of decoded map tiles kept in memory in batch and server mode" value-name:"TILES" default:"1024"`
+		Serve              string   `long:"serve" description:"Run an HTTP server at the given address (e.g. ':8080'), which renders maps for Google Static Maps API compatible requests" value-name:"ADDRESS"`
+		MaxAge             int      `long:"max-age" description:"Cache lifetime of the maps returned by the HTTP server" value-name:"SECONDS" default:"86400"`
+		MaxSize            int      `long:"max-size" description:"Maximum width and height of the maps rendered by the HTTP server" value-name:"PIXELS" default:"2048"`
 	}
 
 	parser := flags.NewParser(&opts, flags.HelpFlag|flags.PassDoubleDash)
@@ -186,13 +436,42 @@ func main() {
 		os.Exit(0)
 	}
 
-	ctx := sm.NewContext()
+	output := outputOptions{
+		fileName:    opts.Output,
+		format:      opts.Format,
+		compression: opts.Compression,
+		quality:     opts.Quality,
+		colors:      opts.Colors,
+		georef:      opts.Georef,
+		strips:      opts.Strips,
+		dpi:         opts.DPI,
+		paper:       opts.Paper,
+	}
 
-	if parser.FindOptionByLongName("type").IsSet() {
-		handleTypeOption(ctx, opts.Type, opts.ThunderforstAPIKey)
+	if parser.FindOptionByLongName("serve").IsSet() {
+		s, err := newServer(opts.Type, opts.ThunderforstAPIKey, opts.UserAgent, opts.MaxAge, opts.MaxSize)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s.memoryCache = sm.NewMemoryTileCache(opts.TileMemory)
+		log.Fatal(s.listenAndServe(opts.Serve))
+	}
+
+	if parser.FindOptionByLongName("batch").IsSet() {
+		settings := batchSettings{
+			jobs:                opts.Jobs,
+			userAgent:           opts.UserAgent,
+			thunderforestAPIKey: opts.ThunderforstAPIKey,
+			memoryCache:         sm.NewMemoryTileCache(opts.TileMemory),
+			output:              output,
+		}
+		if err := runBatch(opts.Batch, settings); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	ctx.SetSize(opts.Width, opts.Height)
+	ctx := createContext(parser, opts.Spec, opts.Type, opts.ThunderforstAPIKey, opts.Width, opts.Height)
 
 	if parser.FindOptionByLongName("zoom").IsSet() {
 		ctx.SetZoom(opts.Zoom)
@@ -222,14 +501,17 @@ func main() {
 	handleMarkersOption(ctx, opts.Markers)
 	handleImageMarkersOption(ctx, opts.ImageMarkers)
 	handleCirclesOption(ctx, opts.Circles)
+	handleCSVOption(ctx, opts.CSV)
 	handlePathsOption(ctx, opts.Paths)
 
-	img, err := ctx.Render()
-	if err != nil {
-		log.Fatal(err)
+	if parser.FindOptionByLongName("save-spec").IsSet() {
+		if err = saveSpec(ctx, opts.SaveSpec); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	if err = gg.SavePNG(opts.Output, img); err != nil {
+	if err = saveOutput(ctx, output); err != nil {
 		log.Fatal(err)
 	}
 }
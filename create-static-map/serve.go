@@ -0,0 +1,348 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flopp/go-coordsparser"
+	sm "github.com/flopp/go-staticmaps"
+	"github.com/golang/geo/s2"
+)
+
+// googleMapTypes maps the map types of the Google Static Maps API to tile providers; the empty string denotes the server's default tile provider
+var googleMapTypes = map[string]string{
+	"roadmap":   "",
+	"satellite": "arcgis-worldimagery",
+	"hybrid":    "arcgis-worldimagery",
+	"terrain":   "opentopomap",
+}
+
+// ignoredTokens are Google Static Maps marker and path tokens without equivalent, which are silently dropped
+var ignoredTokens = []string{"icon:", "anchor:", "scale:", "geodesic:"}
+
+// fileTokens are marker and path tokens referencing local files, which must not be used by HTTP clients
+var fileTokens = []string{"gpx:", "geojson:", "kml:", "wkt:", "shp:"}
+
+// server renders maps from the query parameters of Google Static Maps API compatible requests
+type server struct {
+	tileProviders map[string]*sm.TileProvider
+	defaultType   string
+	userAgent     string
+	maxAge        int
+	maxSize       int
+	memoryCache   *sm.MemoryTileCache
+}
+
+func newServer(defaultType string, thunderforestAPIKey string, userAgent string, maxAge int, maxSize int) (*server, error) {
+	s := &server{
+		tileProviders: sm.GetTileProviders(thunderforestAPIKey),
+		defaultType:   defaultType,
+		userAgent:     userAgent,
+		maxAge:        maxAge,
+		maxSize:       maxSize,
+	}
+	if defaultType != "" && s.tileProviders[defaultType] == nil {
+		return nil, fmt.Errorf("bad map type: %s", defaultType)
+	}
+	return s, nil
+}
+
+// listenAndServe serves requests to '/maps/api/staticmap' (i.e. the path of the Google Static Maps API) and '/staticmap'.
+func (s *server) listenAndServe(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/maps/api/staticmap", s)
+	mux.Handle("/staticmap", s)
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("serving static maps at %s", addr)
+	return httpServer.ListenAndServe()
+}
+
+// ServeHTTP renders the map specified by the query parameters. As the rendered image only depends on these parameters
+// (and the server's configuration), the ETag is derived from them, such that conditional requests can be answered
+// without rendering.
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	ctx, encodeOpts, err := s.newContext(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	etag := fmt.Sprintf("\"%x\"", sha256.Sum256([]byte(s.defaultType+"?"+query.Encode())))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", s.maxAge))
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	img, err := ctx.Render()
+	if err != nil {
+		log.Printf("failed to render %s: %v", r.URL, err)
+		w.Header().Del("ETag")
+		w.Header().Set("Cache-Control", "no-store")
+		http.Error(w, "failed to render map", http.StatusInternalServerError)
+		return
+	}
+	var buf bytes.Buffer
+	if err := sm.EncodeImage(&buf, img, encodeOpts); err != nil {
+		log.Printf("failed to encode %s: %v", r.URL, err)
+		w.Header().Del("ETag")
+		w.Header().Set("Cache-Control", "no-store")
+		http.Error(w, "failed to encode map", http.StatusInternalServerError)
+		return
+	}
+
+	if encodeOpts.Format == sm.FormatJPEG {
+		w.Header().Set("Content-Type", "image/jpeg")
+	} else {
+		w.Header().Set("Content-Type", "image/png")
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Write(buf.Bytes())
+}
+
+// etagMatches checks if the If-None-Match header contains the (strong) etag
+func etagMatches(header string, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// newContext creates a Context and the encode options from the Google Static Maps API query parameters 'size', 'scale',
+// 'center', 'zoom', 'maptype', 'format', 'markers', 'path', and 'visible'; other parameters (e.g. 'key', 'style') are
+// ignored. With 'scale', the image is rendered at the next zoom level(s), and the markers and paths are enlarged accordingly.
+func (s *server) newContext(query url.Values) (*sm.Context, sm.EncodeOptions, error) {
+	ctx := sm.NewContext()
+	ctx.SetMemoryCache(s.memoryCache)
+	if s.userAgent != "" {
+		ctx.SetUserAgent(s.userAgent)
+	}
+
+	encodeOpts, err := parseFormatParameter(query.Get("format"))
+	if err != nil {
+		return nil, encodeOpts, err
+	}
+	if err := s.setMapType(ctx, query.Get("maptype")); err != nil {
+		return nil, encodeOpts, err
+	}
+	scale, err := s.setSize(ctx, query.Get("size"), query.Get("scale"))
+	if err != nil {
+		return nil, encodeOpts, err
+	}
+
+	if center := query.Get("center"); center != "" {
+		lat, lng, err := coordsparser.Parse(center)
+		if err != nil {
+			return nil, encodeOpts, fmt.Errorf("bad center: %v", err)
+		}
+		ctx.SetCenter(s2.LatLngFromDegrees(lat, lng))
+	}
+	if zoom := query.Get("zoom"); zoom != "" {
+		z, err := strconv.Atoi(zoom)
+		if err != nil || z < 0 || z > 30 {
+			return nil, encodeOpts, fmt.Errorf("bad zoom: '%s'", zoom)
+		}
+		for ; scale > 1; scale /= 2 {
+			z++
+		}
+		ctx.SetZoom(z)
+	}
+
+	objects, err := parseObjectParameters(query, float64(scale))
+	if err != nil {
+		return nil, encodeOpts, err
+	}
+	if len(objects) == 0 && query.Get("center") == "" {
+		return nil, encodeOpts, errors.New("missing 'center', 'markers', 'path', or 'visible' parameter")
+	}
+	for _, object := range objects {
+		ctx.AddObject(object)
+	}
+	return ctx, encodeOpts, nil
+}
+
+func (s *server) setMapType(ctx *sm.Context, mapType string) error {
+	name := s.defaultType
+	if mapType != "" {
+		if googleName, ok := googleMapTypes[mapType]; ok {
+			if googleName != "" {
+				name = googleName
+			}
+		} else if s.tileProviders[mapType] != nil {
+			name = mapType
+		} else {
+			return fmt.Errorf("bad maptype: '%s'", mapType)
+		}
+	}
+	if name != "" {
+		ctx.SetTileProvider(s.tileProviders[name])
+	}
+	return nil
+}
+
+// setSize sets the image size from the 'size' ("WIDTHxHEIGHT") and 'scale' (1, 2, or 4) parameters, and returns the scale
+func (s *server) setSize(ctx *sm.Context, size string, scale string) (int, error) {
+	if size == "" {
+		return 0, errors.New("missing 'size' parameter")
+	}
+	var width, height int
+	if n, err := fmt.Sscanf(size, "%dx%d", &width, &height); err != nil || n != 2 || width <= 0 || height <= 0 {
+		return 0, fmt.Errorf("bad size: '%s'", size)
+	}
+
+	factor := 1
+	if scale != "" {
+		var err error
+		if factor, err = strconv.Atoi(scale); err != nil || (factor != 1 && factor != 2 && factor != 4) {
+			return 0, fmt.Errorf("bad scale: '%s'", scale)
+		}
+	}
+	if width*factor > s.maxSize || height*factor > s.maxSize {
+		return 0, fmt.Errorf("size exceeds maximum of %dx%d pixels", s.maxSize, s.maxSize)
+	}
+	ctx.SetSize(width*factor, height*factor)
+	return factor, nil
+}
+
+func parseFormatParameter(format string) (sm.EncodeOptions, error) {
+	switch format {
+	case "", "png", "png32":
+		return sm.EncodeOptions{Format: sm.FormatPNG}, nil
+	case "png8":
+		return sm.EncodeOptions{Format: sm.FormatPalettedPNG}, nil
+	case "jpg", "jpg-baseline":
+		return sm.EncodeOptions{Format: sm.FormatJPEG}, nil
+	}
+	return sm.EncodeOptions{}, fmt.Errorf("unsupported format: '%s'", format)
+}
+
+// parseObjectParameters creates the map objects from the 'markers', 'path', and 'visible' parameters; paths with a
+// 'fillcolor' become areas, and the locations of 'visible' are added as invisible path
+func parseObjectParameters(query url.Values, scale float64) ([]sm.MapObject, error) {
+	objects := make([]sm.MapObject, 0)
+	for _, parameter := range query["markers"] {
+		tokens, err := filterTokens(parameter)
+		if err != nil {
+			return nil, err
+		}
+		markers, err := sm.ParseMarkerString(strings.Join(tokens, "|"))
+		if err != nil {
+			return nil, fmt.Errorf("bad markers: %v", err)
+		}
+		for _, marker := range markers {
+			marker.Size *= scale
+			objects = append(objects, marker)
+		}
+	}
+
+	for _, parameter := range query["path"] {
+		object, err := parsePathParameter(parameter, scale)
+		if err != nil {
+			return nil, err
+		}
+		if object != nil {
+			objects = append(objects, object)
+		}
+	}
+
+	for _, parameter := range query["visible"] {
+		positions := make([]s2.LatLng, 0)
+		for _, token := range strings.Split(parameter, "|") {
+			lat, lng, err := coordsparser.Parse(token)
+			if err != nil {
+				return nil, fmt.Errorf("bad visible: %v", err)
+			}
+			positions = append(positions, s2.LatLngFromDegrees(lat, lng))
+		}
+		objects = append(objects, sm.NewPath(positions, color.Transparent, 0))
+	}
+	return objects, nil
+}
+
+func parsePathParameter(parameter string, scale float64) (sm.MapObject, error) {
+	tokens, err := filterTokens(parameter)
+	if err != nil {
+		return nil, err
+	}
+	isArea := false
+	for i, token := range tokens {
+		if strings.HasPrefix(token, "fillcolor:") {
+			tokens[i] = "fill:" + strings.TrimPrefix(token, "fillcolor:")
+			isArea = true
+		}
+	}
+
+	if isArea {
+		area, err := sm.ParseAreaString(strings.Join(tokens, "|"))
+		if err != nil {
+			return nil, fmt.Errorf("bad path: %v", err)
+		}
+		area.Weight *= scale
+		return area, nil
+	}
+
+	paths, err := sm.ParsePathString(strings.Join(tokens, "|"))
+	if err != nil {
+		return nil, fmt.Errorf("bad path: %v", err)
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	paths[0].Weight *= scale
+	return paths[0], nil
+}
+
+// filterTokens splits a 'markers' or 'path' parameter into its tokens, drops the ignored tokens, and rejects file references
+func filterTokens(parameter string) ([]string, error) {
+	tokens := make([]string, 0)
+	for _, token := range strings.Split(parameter, "|") {
+		if hasAnyPrefix(token, fileTokens) {
+			return nil, fmt.Errorf("file references are not allowed: '%s'", token)
+		}
+		if !hasAnyPrefix(token, ignoredTokens) {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
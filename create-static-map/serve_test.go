@@ -0,0 +1,121 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *server {
+	s, err := newServer("none", "", "", 3600, 640)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestServeStatus(t *testing.T) {
+	s := newTestServer(t)
+	for _, test := range []struct {
+		query  string
+		status int
+		// contentType is checked for successful requests, message for failed requests
+		contentType string
+		message     string
+	}{
+		{"size=100x100&center=52.5,13.4&zoom=10", http.StatusOK, "image/png", ""},
+		{"size=100x100&markers=color:red|52.5,13.4&format=jpg", http.StatusOK, "image/jpeg", ""},
+		{"size=100x100&path=color:0x0000ff|weight:3|52.5,13.4|52.6,13.5&scale=2", http.StatusOK, "image/png", ""},
+		{"size=100x100&path=fillcolor:0x00ff0080|52.5,13.4|52.6,13.5|52.6,13.4&format=png8", http.StatusOK, "image/png", ""},
+		{"size=100x100&markers=icon:http://example.com/x.png|52.5,13.4", http.StatusOK, "image/png", ""},
+		{"center=52.5,13.4&zoom=10", http.StatusBadRequest, "", "missing 'size'"},
+		{"size=100&center=52.5,13.4", http.StatusBadRequest, "", "bad size"},
+		{"size=100x100", http.StatusBadRequest, "", "missing 'center'"},
+		{"size=100x100&center=x", http.StatusBadRequest, "", "bad center"},
+		{"size=100x100&center=52.5,13.4&zoom=31", http.StatusBadRequest, "", "bad zoom"},
+		{"size=100x100&center=52.5,13.4&scale=3", http.StatusBadRequest, "", "bad scale"},
+		{"size=100x100&center=52.5,13.4&format=gif", http.StatusBadRequest, "", "unsupported format"},
+		{"size=100x100&center=52.5,13.4&maptype=unknown", http.StatusBadRequest, "", "bad maptype"},
+		{"size=641x100&center=52.5,13.4", http.StatusBadRequest, "", "exceeds maximum"},
+		{"size=400x400&center=52.5,13.4&scale=2", http.StatusBadRequest, "", "exceeds maximum"},
+		{"size=100x100&visible=x", http.StatusBadRequest, "", "bad visible"},
+	} {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/maps/api/staticmap?"+test.query, nil))
+		if w.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d: %s", test.query, test.status, w.Code, w.Body.String())
+			continue
+		}
+		if test.contentType != "" && w.Header().Get("Content-Type") != test.contentType {
+			t.Errorf("%s: unexpected content type: %s", test.query, w.Header().Get("Content-Type"))
+		}
+		if test.message != "" && !strings.Contains(w.Body.String(), test.message) {
+			t.Errorf("%s: unexpected message: %s", test.query, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/maps/api/staticmap?size=100x100&center=52.5,13.4", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("unexpected response to POST: %d", w.Code)
+	}
+}
+
+func TestServeRejectsFileTokens(t *testing.T) {
+	s := newTestServer(t)
+	for _, query := range []string{
+		"size=100x100&path=gpx:/etc/passwd",
+		"size=100x100&path=color:red|geojson:/etc/passwd",
+		"size=100x100&path=fillcolor:red|kml:/etc/passwd",
+		"size=100x100&markers=wkt:/etc/passwd",
+		"size=100x100&markers=shp:/etc/passwd",
+	} {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/maps/api/staticmap?"+query, nil))
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "file references are not allowed") {
+			t.Errorf("%s: file reference not rejected: %d %s", query, w.Code, w.Body.String())
+		}
+	}
+	for _, token := range fileTokens {
+		if _, err := filterTokens("color:red|" + token + "x"); err == nil {
+			t.Errorf("token '%s' not rejected", token)
+		}
+	}
+}
+
+func TestServeETag(t *testing.T) {
+	s := newTestServer(t)
+	const target = "/staticmap?size=100x100&center=52.5,13.4&zoom=10"
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || w.Header().Get("Cache-Control") != "public, max-age=3600" {
+		t.Fatalf("unexpected response: %d %v", w.Code, w.Header())
+	}
+
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	r.Header.Set("If-None-Match", `"other", W/`+etag)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("expected 304, got %d", w.Code)
+	}
+
+	for _, test := range []struct {
+		header string
+		match  bool
+	}{
+		{"", false},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"x", "abc"`, true},
+		{`*`, true},
+		{`"abcd"`, false},
+		{`abc`, false},
+	} {
+		if etagMatches(test.header, `"abc"`) != test.match {
+			t.Errorf("etagMatches(%s): expected %v", test.header, test.match)
+		}
+	}
+}
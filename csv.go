@@ -0,0 +1,366 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/flopp/go-coordsparser"
+	"github.com/golang/geo/s2"
+)
+
+// DefaultPalette is the categorical palette used for CSV categories, if CSVOptions.Palette is empty
+var DefaultPalette = []color.Color{
+	color.RGBA{0x1f, 0x77, 0xb4, 0xff},
+	color.RGBA{0xff, 0x7f, 0x0e, 0xff},
+	color.RGBA{0x2c, 0xa0, 0x2c, 0xff},
+	color.RGBA{0xd6, 0x27, 0x28, 0xff},
+	color.RGBA{0x94, 0x67, 0xbd, 0xff},
+	color.RGBA{0x8c, 0x56, 0x4b, 0xff},
+	color.RGBA{0xe3, 0x77, 0xc2, 0xff},
+	color.RGBA{0x7f, 0x7f, 0x7f, 0xff},
+	color.RGBA{0xbc, 0xbd, 0x22, 0xff},
+	color.RGBA{0x17, 0xbe, 0xcf, 0xff},
+}
+
+// CSVOptions maps the columns of a CSV file (given by the names of the header row) to the positions and styles of
+// Markers or Circles.
+type CSVOptions struct {
+	// Lat and Lng are the latitude and longitude columns; if both are empty, columns named "lat"/"latitude" and
+	// "lng"/"lon"/"long"/"longitude" are used
+	Lat string
+	Lng string
+	// Position is a column with combined coordinates (e.g. "52.5,13.4"), which is used instead of Lat and Lng
+	Position string
+	// Label is the column with the marker labels
+	Label string
+	// Category is the column determining the colors: each distinct category is assigned the next color of Palette (in
+	// order of appearance, wrapping around); defaults to DefaultPalette
+	Category string
+	Palette  []color.Color
+	// Value is the numeric column determining the sizes: values are scaled linearly from the column's minimum and
+	// maximum to MinSize and MaxSize, i.e. marker sizes in pixels (default: 8 and 32) or circle radii in meters
+	// (default: 100 and 1000); unset bounds are defaulted individually
+	Value   string
+	MinSize float64
+	MaxSize float64
+	// Circles selects Circles instead of Markers; without Value, circles have a radius of 100 meters
+	Circles bool
+	// Style is the style of markers and circles without category or value (nil selects NewFeatureStyle); circles use
+	// Style.Fill, or the category color with 50% opacity
+	Style *FeatureStyle
+	// Comma is the field delimiter; defaults to ','
+	Comma rune
+}
+
+// LoadCSV loads the rows of a CSV file as Markers or Circles (see CSVOptions).
+func LoadCSV(fileName string, opts CSVOptions) ([]MapObject, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	objects, err := ParseCSV(file, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fileName, err)
+	}
+	return objects, nil
+}
+
+// csvRow is a row of a CSV file, whose value is only valid if hasValue is true
+type csvRow struct {
+	pos      s2.LatLng
+	label    string
+	category string
+	value    float64
+	hasValue bool
+}
+
+// ParseCSV reads the rows of a CSV document as Markers or Circles (see CSVOptions).
+func ParseCSV(r io.Reader, opts CSVOptions) ([]MapObject, error) {
+	reader := csv.NewReader(r)
+	if opts.Comma != 0 {
+		reader.Comma = opts.Comma
+	}
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns, err := opts.columns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]csvRow, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		row, err := columns.row(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		rows = append(rows, row)
+	}
+	return opts.mapObjects(rows), nil
+}
+
+// csvColumns are the indices of the mapped columns; -1 denotes unmapped columns
+type csvColumns struct {
+	lat, lng, position, label, category, value int
+}
+
+func (opts *CSVOptions) columns(header []string) (*csvColumns, error) {
+	indices := make(map[string]int)
+	for i, name := range header {
+		indices[strings.TrimSpace(name)] = i
+	}
+	find := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		if i, ok := indices[name]; ok {
+			return i, nil
+		}
+		return -1, fmt.Errorf("missing CSV column: '%s'", name)
+	}
+	findAny := func(names ...string) string {
+		for i, name := range header {
+			for _, n := range names {
+				if strings.EqualFold(strings.TrimSpace(name), n) {
+					return header[i]
+				}
+			}
+		}
+		return ""
+	}
+
+	lat, lng := opts.Lat, opts.Lng
+	if opts.Position == "" && lat == "" && lng == "" {
+		lat, lng = findAny("lat", "latitude"), findAny("lng", "lon", "long", "longitude")
+		if lat == "" || lng == "" {
+			return nil, errors.New("missing CSV position columns")
+		}
+	}
+	c := &csvColumns{}
+	var err error
+	for _, column := range []struct {
+		index *int
+		name  string
+	}{{&c.lat, lat}, {&c.lng, lng}, {&c.position, opts.Position}, {&c.label, opts.Label}, {&c.category, opts.Category}, {&c.value, opts.Value}} {
+		if *column.index, err = find(strings.TrimSpace(column.name)); err != nil {
+			return nil, err
+		}
+	}
+	if c.position < 0 && (c.lat < 0 || c.lng < 0) {
+		return nil, errors.New("missing CSV position columns")
+	}
+	return c, nil
+}
+
+func (c *csvColumns) row(record []string) (csvRow, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	var row csvRow
+	if c.position >= 0 {
+		lat, lng, err := coordsparser.Parse(field(c.position))
+		if err != nil {
+			return row, err
+		}
+		row.pos = s2.LatLngFromDegrees(lat, lng)
+	} else {
+		lat, err := strconv.ParseFloat(field(c.lat), 64)
+		if err != nil {
+			return row, fmt.Errorf("bad latitude: '%s'", field(c.lat))
+		}
+		lng, err := strconv.ParseFloat(field(c.lng), 64)
+		if err != nil {
+			return row, fmt.Errorf("bad longitude: '%s'", field(c.lng))
+		}
+		row.pos = s2.LatLngFromDegrees(lat, lng)
+	}
+	row.label = field(c.label)
+	row.category = field(c.category)
+	if s := field(c.value); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return row, fmt.Errorf("bad value: '%s'", s)
+		}
+		row.value, row.hasValue = v, true
+	}
+	return row, nil
+}
+
+func (opts *CSVOptions) mapObjects(rows []csvRow) []MapObject {
+	style := opts.Style
+	if style == nil {
+		style = NewFeatureStyle()
+	}
+	palette := opts.Palette
+	if len(palette) == 0 {
+		palette = DefaultPalette
+	}
+	minSize, maxSize := opts.sizeRange()
+	minValue, maxValue := math.Inf(1), math.Inf(-1)
+	for _, row := range rows {
+		if row.hasValue {
+			minValue, maxValue = math.Min(minValue, row.value), math.Max(maxValue, row.value)
+		}
+	}
+
+	categories := make(map[string]color.Color)
+	objects := make([]MapObject, 0, len(rows))
+	for _, row := range rows {
+		col, fill := style.MarkerColor, style.Fill
+		if opts.Circles {
+			col = style.Stroke
+		}
+		if opts.Category != "" {
+			c, ok := categories[row.category]
+			if !ok {
+				c = palette[len(categories)%len(palette)]
+				categories[row.category] = c
+			}
+			col = c
+			fill = withOpacity(c, 0.5)
+		}
+
+		size := style.MarkerSize
+		if opts.Circles {
+			size = 100.0
+		}
+		if row.hasValue {
+			size = minSize
+			if maxValue > minValue {
+				size += (row.value - minValue) / (maxValue - minValue) * (maxSize - minSize)
+			}
+		}
+
+		if opts.Circles {
+			objects = append(objects, NewCircle(row.pos, col, fill, size, style.StrokeWidth))
+		} else {
+			marker := NewMarker(row.pos, col, size)
+			marker.Label = row.label
+			objects = append(objects, marker)
+		}
+	}
+	return objects
+}
+
+// sizeRange returns MinSize and MaxSize, each defaulting on its own; a defaulted bound does not exceed the given one
+func (opts *CSVOptions) sizeRange() (float64, float64) {
+	minSize, maxSize := 8.0, 32.0
+	if opts.Circles {
+		minSize, maxSize = 100.0, 1000.0
+	}
+	switch {
+	case opts.MinSize > 0 && opts.MaxSize > 0:
+		return opts.MinSize, opts.MaxSize
+	case opts.MinSize > 0:
+		return opts.MinSize, math.Max(opts.MinSize, maxSize)
+	case opts.MaxSize > 0:
+		return math.Min(minSize, opts.MaxSize), opts.MaxSize
+	}
+	return minSize, maxSize
+}
+
+// ParseCSVString parses a string of column mappings ('lat:', 'lng:', 'position:', 'label:', 'category:', 'value:'),
+// options ('palette:' with comma separated colors, 'minsize:', 'maxsize:', 'type:' with 'marker' or 'circle',
+// 'delimiter:' with a single character or 'tab'), default styles ('color:', 'fill:', 'size:', 'weight:'), and CSV file
+// names, and returns the markers or circles of the files.
+func ParseCSVString(s string) ([]MapObject, error) {
+	opts := CSVOptions{Style: NewFeatureStyle()}
+	columns := map[string]*string{"lat:": &opts.Lat, "lng:": &opts.Lng, "position:": &opts.Position, "label:": &opts.Label, "category:": &opts.Category, "value:": &opts.Value}
+	objects := make([]MapObject, 0)
+	for _, ss := range strings.Split(s, "|") {
+		if i := strings.Index(ss, ":"); i >= 0 && columns[ss[:i+1]] != nil {
+			*columns[ss[:i+1]] = ss[i+1:]
+		} else if isOption, err := opts.parseOptionToken(ss); isOption {
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			fileObjects, err := LoadCSV(ss, opts)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, fileObjects...)
+		}
+	}
+	return objects, nil
+}
+
+// parseOptionToken parses the option and style tokens of ParseCSVString
+func (opts *CSVOptions) parseOptionToken(token string) (bool, error) {
+	var err error
+	if ok, suffix := hasPrefix(token, "palette:"); ok {
+		opts.Palette = nil
+		for _, c := range strings.Split(suffix, ",") {
+			col, err := ParseColorString(c)
+			if err != nil {
+				return true, err
+			}
+			opts.Palette = append(opts.Palette, col)
+		}
+	} else if ok, suffix := hasPrefix(token, "minsize:"); ok {
+		opts.MinSize, err = strconv.ParseFloat(suffix, 64)
+	} else if ok, suffix := hasPrefix(token, "maxsize:"); ok {
+		opts.MaxSize, err = strconv.ParseFloat(suffix, 64)
+	} else if ok, suffix := hasPrefix(token, "type:"); ok {
+		if suffix != "marker" && suffix != "circle" {
+			return true, fmt.Errorf("bad CSV type: '%s'", suffix)
+		}
+		opts.Circles = suffix == "circle"
+	} else if ok, suffix := hasPrefix(token, "delimiter:"); ok {
+		if suffix == "tab" {
+			suffix = "\t"
+		}
+		if len([]rune(suffix)) != 1 {
+			return true, fmt.Errorf("bad CSV delimiter: '%s'", suffix)
+		}
+		opts.Comma = []rune(suffix)[0]
+	} else {
+		return opts.Style.parseStyleToken(token)
+	}
+	return true, err
+}
+
+// parseStyleToken parses the 'color:', 'fill:', 'size:', and 'weight:' tokens
+func (s *FeatureStyle) parseStyleToken(token string) (bool, error) {
+	var err error
+	if ok, suffix := hasPrefix(token, "color:"); ok {
+		if s.MarkerColor, err = ParseColorString(suffix); err == nil {
+			s.Stroke = s.MarkerColor
+		}
+	} else if ok, suffix := hasPrefix(token, "fill:"); ok {
+		s.Fill, err = ParseColorString(suffix)
+	} else if ok, suffix := hasPrefix(token, "size:"); ok {
+		s.MarkerSize, err = parseSizeString(suffix)
+	} else if ok, suffix := hasPrefix(token, "weight:"); ok {
+		s.StrokeWidth, err = strconv.ParseFloat(suffix, 64)
+	} else {
+		return false, nil
+	}
+	return true, err
+}
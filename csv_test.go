@@ -0,0 +1,90 @@
+package sm
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+const testCSV = `name,Latitude,Lon,kind,count
+A,52.5,13.4,shop,10
+B,52.6,13.5,cafe,30
+C,52.7,13.6,shop,20
+`
+
+func TestParseCSV(t *testing.T) {
+	objects, err := ParseCSV(strings.NewReader(testCSV), CSVOptions{Label: "name", Category: "kind", Value: "count"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("unexpected number of objects: %d", len(objects))
+	}
+	expected := []struct {
+		pos   s2.LatLng
+		color color.Color
+		size  float64
+	}{
+		{s2.LatLngFromDegrees(52.5, 13.4), DefaultPalette[0], 8},
+		{s2.LatLngFromDegrees(52.6, 13.5), DefaultPalette[1], 32},
+		{s2.LatLngFromDegrees(52.7, 13.6), DefaultPalette[0], 20},
+	}
+	for i, e := range expected {
+		marker, ok := objects[i].(*Marker)
+		if !ok || marker.Position != e.pos || marker.Color != e.color || marker.Size != e.size {
+			t.Errorf("unexpected marker %d: %v", i, objects[i])
+		}
+	}
+
+	for _, opts := range []CSVOptions{{Lat: "lat", Lng: "lng"}, {Position: "name"}, {Value: "name"}} {
+		if _, err := ParseCSV(strings.NewReader(testCSV), opts); err == nil {
+			t.Errorf("error expected for %v", opts)
+		}
+	}
+}
+
+func TestParseCSVString(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.csv")
+	if err := os.WriteFile(fileName, []byte(strings.ReplaceAll(testCSV, ",", ";")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := ParseCSVString("type:circle|delimiter:;|value:count|minsize:50|maxsize:250|color:blue|" + fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("unexpected number of objects: %d", len(objects))
+	}
+	if circle, ok := objects[2].(*Circle); !ok || circle.Radius != 150 || circle.Color != (color.RGBA{0, 0, 0xff, 0xff}) {
+		t.Errorf("unexpected circle: %v", objects[2])
+	}
+
+	if _, err := ParseCSVString("type:square|" + fileName); err == nil {
+		t.Error("error expected for bad type")
+	}
+}
+
+func TestCSVSizeRange(t *testing.T) {
+	for _, test := range []struct {
+		opts     CSVOptions
+		min, max float64
+	}{
+		{CSVOptions{}, 8, 32},
+		{CSVOptions{Circles: true}, 100, 1000},
+		{CSVOptions{MaxSize: 50}, 8, 50},
+		{CSVOptions{MaxSize: 4}, 4, 4},
+		{CSVOptions{MinSize: 16}, 16, 32},
+		{CSVOptions{MinSize: 40}, 40, 40},
+		{CSVOptions{Circles: true, MinSize: 500}, 500, 1000},
+		{CSVOptions{MinSize: 10, MaxSize: 20}, 10, 20},
+	} {
+		if min, max := test.opts.sizeRange(); min != test.min || max != test.max {
+			t.Errorf("%+v: expected %v-%v, got %v-%v", test.opts, test.min, test.max, min, max)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/geo/s2"
+)
+
+// Ellipse represents a geodesic ellipse on the map
+type Ellipse struct {
+	MapObject
+	Position  s2.LatLng
+	Color     color.Color
+	Fill      color.Color
+	Weight    float64
+	SemiMajor float64 // in m.
+	SemiMinor float64 // in m.
+	Rotation  float64 // azimuth of the major axis in degrees, clockwise from north
+}
+
+// NewEllipse creates a new ellipse
+func NewEllipse(pos s2.LatLng, col, fill color.Color, semiMajor, semiMinor, rotation, weight float64) *Ellipse {
+	return &Ellipse{
+		Position:  pos,
+		Color:     col,
+		Fill:      fill,
+		Weight:    weight,
+		SemiMajor: semiMajor,
+		SemiMinor: semiMinor,
+		Rotation:  rotation,
+	}
+}
+
+// Ring returns the densified polygon of points on the ellipse around the center.
+func (m *Ellipse) Ring() []s2.LatLng {
+	rotation := m.Rotation * math.Pi / 180.0
+	return geodesicRing(m.Position, 0, 2*math.Pi, func(bearing float64) float64 {
+		a := m.SemiMajor * math.Sin(bearing-rotation)
+		b := m.SemiMinor * math.Cos(bearing-rotation)
+		return m.SemiMajor * m.SemiMinor / math.Sqrt(a*a+b*b)
+	})
+}
+
+// ExtraMarginPixels returns the left, top, right, bottom pixel margin of the Ellipse object, which is exactly the line width.
+func (m *Ellipse) ExtraMarginPixels() (float64, float64, float64, float64) {
+	return m.Weight, m.Weight, m.Weight, m.Weight
+}
+
+// Bounds returns the geographical boundary rect (excluding the actual pixel dimensions).
+func (m *Ellipse) Bounds() s2.Rect {
+	if m.SemiMajor <= 0 || m.SemiMinor <= 0 {
+		return s2.RectFromLatLng(m.Position)
+	}
+	return ringBounds(m.Ring())
+}
+
+// Draw draws the object in the given graphical context.
+func (m *Ellipse) Draw(gc *gg.Context, trans *Transformer) {
+	m.DrawCanvas(gc, trans)
+}
+
+// DrawCanvas draws the object to the given canvas.
+func (m *Ellipse) DrawCanvas(gc Canvas, trans *Transformer) {
+	if !CanDisplay(m.Position) {
+		log.Printf("Ellipse coordinates not displayable: %f/%f", m.Position.Lat.Degrees(), m.Position.Lng.Degrees())
+		return
+	}
+	if m.SemiMajor <= 0 || m.SemiMinor <= 0 {
+		return
+	}
+
+	gc.ClearPath()
+	gc.SetLineWidth(m.Weight)
+	gc.SetLineCap(gg.LineCapRound)
+	gc.SetLineJoin(gg.LineJoinRound)
+	drawRing(gc, trans, m.Position, m.Ring())
+	gc.SetColor(m.Fill)
+	gc.FillPreserve()
+	gc.SetColor(m.Color)
+	gc.Stroke()
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ImageFormat specifies the encoding of raster images
+type ImageFormat int
+
+const (
+	// FormatPNG encodes 32 bit RGBA PNG images
+	FormatPNG ImageFormat = iota
+	// FormatJPEG encodes JPEG images; transparent areas become white
+	FormatJPEG
+	// FormatPalettedPNG encodes PNG images with a reduced color palette, which results in considerably smaller files
+	FormatPalettedPNG
+)
+
+// EncodeOptions specifies the encoding of raster images
+type EncodeOptions struct {
+	// Format is the image format
+	Format ImageFormat
+	// CompressionLevel is the compression level of PNG images
+	CompressionLevel png.CompressionLevel
+	// Quality is the quality of JPEG images (1-100); defaults to 90
+	Quality int
+	// Colors is the maximum number of palette colors of paletted PNG images (2-256); defaults to 256
+	Colors int
+}
+
+// ParseImageFormat parses the name of an image format, i.e. "png", "jpeg" (or "jpg"), and "png8" (or "paletted").
+func ParseImageFormat(s string) (ImageFormat, error) {
+	switch strings.ToLower(s) {
+	case "png":
+		return FormatPNG, nil
+	case "jpeg", "jpg":
+		return FormatJPEG, nil
+	case "png8", "paletted":
+		return FormatPalettedPNG, nil
+	case "webp":
+		return FormatPNG, fmt.Errorf("encoding of image format '%s' is not supported", s)
+	}
+	return FormatPNG, fmt.Errorf("unknown image format: '%s'", s)
+}
+
+// ImageFormatFromFileName determines the image format from the extension of the file name; file names without extension
+// yield FormatPNG, unknown extensions yield an error.
+func ImageFormatFromFileName(fileName string) (ImageFormat, error) {
+	ext := strings.TrimPrefix(filepath.Ext(fileName), ".")
+	if ext == "" {
+		return FormatPNG, nil
+	}
+	return ParseImageFormat(ext)
+}
+
+// EncodeImage writes img to w using the encoding specified by opts.
+func EncodeImage(w io.Writer, img image.Image, opts EncodeOptions) error {
+	switch opts.Format {
+	case FormatPNG:
+		encoder := png.Encoder{CompressionLevel: opts.CompressionLevel}
+		return encoder.Encode(w, img)
+	case FormatJPEG:
+		quality := opts.Quality
+		if quality <= 0 {
+			quality = 90
+		}
+		opaque := image.NewRGBA(img.Bounds())
+		draw.Draw(opaque, opaque.Bounds(), image.White, image.Point{}, draw.Src)
+		draw.Draw(opaque, opaque.Bounds(), img, img.Bounds().Min, draw.Over)
+		return jpeg.Encode(w, opaque, &jpeg.Options{Quality: quality})
+	case FormatPalettedPNG:
+		colors := opts.Colors
+		if colors <= 0 || colors > 256 {
+			colors = 256
+		}
+		encoder := png.Encoder{CompressionLevel: opts.CompressionLevel}
+		return encoder.Encode(w, quantize(img, colors))
+	}
+	return fmt.Errorf("unknown image format: %d", opts.Format)
+}
+
+// colorCount is a color with its number of occurrences
+type colorCount struct {
+	col   color.RGBA
+	count int
+}
+
+// quantize converts img to a paletted image with at most n colors; the palette is determined by the median cut algorithm,
+// and the image is dithered, unless it has no more than n distinct colors.
+func quantize(img image.Image, n int) *image.Paletted {
+	b := img.Bounds()
+	counts := make(map[color.RGBA]int)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			counts[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)]++
+		}
+	}
+	colors := make([]colorCount, 0, len(counts))
+	for col, count := range counts {
+		colors = append(colors, colorCount{col, count})
+	}
+
+	paletted := image.NewPaletted(b, nil)
+	if len(colors) <= n {
+		for _, c := range colors {
+			paletted.Palette = append(paletted.Palette, c.col)
+		}
+		draw.Draw(paletted, b, img, b.Min, draw.Src)
+		return paletted
+	}
+
+	paletted.Palette = medianCut(colors, n)
+	draw.FloydSteinberg.Draw(paletted, b, img, b.Min)
+	return paletted
+}
+
+// medianCut repeatedly splits the box (set of colors) with the largest channel range at the weighted median of that channel,
+// until there are n boxes; the palette consists of the boxes' weighted average colors.
+func medianCut(colors []colorCount, n int) color.Palette {
+	boxes := [][]colorCount{colors}
+	for len(boxes) < n {
+		best, bestChannel, bestRange := -1, 0, 0
+		for i, box := range boxes {
+			if len(box) < 2 {
+				continue
+			}
+			if channel, r := widestChannel(box); r > bestRange {
+				best, bestChannel, bestRange = i, channel, r
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		box := boxes[best]
+		sort.Slice(box, func(i, j int) bool {
+			return channelValue(box[i].col, bestChannel) < channelValue(box[j].col, bestChannel)
+		})
+		total := 0
+		for _, c := range box {
+			total += c.count
+		}
+		split, sum := 1, box[0].count
+		for split < len(box)-1 && 2*sum < total {
+			sum += box[split].count
+			split++
+		}
+		boxes[best] = box[:split]
+		boxes = append(boxes, box[split:])
+	}
+
+	palette := make(color.Palette, 0, len(boxes))
+	for _, box := range boxes {
+		var r, g, b, a, total int
+		for _, c := range box {
+			r += int(c.col.R) * c.count
+			g += int(c.col.G) * c.count
+			b += int(c.col.B) * c.count
+			a += int(c.col.A) * c.count
+			total += c.count
+		}
+		palette = append(palette, color.RGBA{uint8(r / total), uint8(g / total), uint8(b / total), uint8(a / total)})
+	}
+	return palette
+}
+
+// widestChannel returns the channel (0-3: R, G, B, A) with the largest value range within the box, and that range
+func widestChannel(box []colorCount) (int, int) {
+	bestChannel, bestRange := 0, -1
+	for channel := 0; channel < 4; channel++ {
+		lo, hi := 255, 0
+		for _, c := range box {
+			v := channelValue(c.col, channel)
+			if v < lo {
+				lo = v
+			}
+			if v > hi {
+				hi = v
+			}
+		}
+		if hi-lo > bestRange {
+			bestChannel, bestRange = channel, hi-lo
+		}
+	}
+	return bestChannel, bestRange
+}
+
+func channelValue(col color.RGBA, channel int) int {
+	switch channel {
+	case 0:
+		return int(col.R)
+	case 1:
+		return int(col.G)
+	case 2:
+		return int(col.B)
+	}
+	return int(col.A)
+}
@@ -0,0 +1,53 @@
+package sm
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestEncodePalettedPNG(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.RGBA{uint8(4 * x), uint8(4 * y), 128, 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeImage(&buf, img, EncodeOptions{Format: FormatPalettedPNG, Colors: 16}); err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	paletted, ok := decoded.(*image.Paletted)
+	if !ok {
+		t.Fatalf("expected paletted image, got %T", decoded)
+	}
+	if len(paletted.Palette) > 16 {
+		t.Errorf("expected at most 16 colors, got %d", len(paletted.Palette))
+	}
+}
+
+func TestImageFormatFromFileName(t *testing.T) {
+	for name, expected := range map[string]ImageFormat{
+		"map.png":  FormatPNG,
+		"map.JPG":  FormatJPEG,
+		"map.jpeg": FormatJPEG,
+		"map.png8": FormatPalettedPNG,
+		"map":      FormatPNG,
+	} {
+		if actual, err := ImageFormatFromFileName(name); err != nil || actual != expected {
+			t.Errorf("ImageFormatFromFileName(%s): expected %d, got %d (%v)", name, expected, actual, err)
+		}
+	}
+	for _, name := range []string{"map.foo", "map.webp"} {
+		if _, err := ImageFormatFromFileName(name); err == nil {
+			t.Errorf("ImageFormatFromFileName(%s): error expected", name)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// This is an example on how to render an animation of a GPX track.
+
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	sm "github.com/flopp/go-staticmaps"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatalf("usage: %s TRACK.gpx", os.Args[0])
+	}
+
+	track, err := sm.LoadGPXTrack(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := sm.NewContext()
+	ctx.SetSize(600, 400)
+
+	animation, err := ctx.RenderAnimation(track, sm.AnimationOptions{Frames: 60, Delay: 50 * time.Millisecond, ByTime: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Create("track-animation.gif")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	if err := animation.EncodeGIF(file); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"image/color"
+	"unicode/utf8"
+)
+
+// FeatureStyle is the default style of map objects created from geodata files (e.g. GeoJSON, KML), which is overridden
+// by the styles given in the files
+type FeatureStyle struct {
+	Stroke      color.Color
+	StrokeWidth float64
+	Fill        color.Color
+	MarkerColor color.Color
+	MarkerSize  float64
+}
+
+// NewFeatureStyle creates the default style of the simplestyle-spec (see https://github.com/mapbox/simplestyle-spec)
+func NewFeatureStyle() *FeatureStyle {
+	s := new(FeatureStyle)
+	s.Stroke = color.RGBA{0x55, 0x55, 0x55, 0xff}
+	s.StrokeWidth = 2.0
+	s.Fill = color.NRGBA{0x55, 0x55, 0x55, 0x99}
+	s.MarkerColor = color.RGBA{0x7e, 0x7e, 0x7e, 0xff}
+	s.MarkerSize = 16.0
+	return s
+}
+
+// markerLabel returns the name, if it is short enough (up to two characters) to be used as marker label, or an empty string
+func markerLabel(name string) string {
+	if utf8.RuneCountInString(name) <= 2 {
+		return name
+	}
+	return ""
+}
+
+// markersOf returns the Markers of the objects
+func markersOf(objects []MapObject) []*Marker {
+	markers := make([]*Marker, 0)
+	for _, object := range objects {
+		if m, ok := object.(*Marker); ok {
+			markers = append(markers, m)
+		}
+	}
+	return markers
+}
+
+// pathsOf returns the Paths of the objects
+func pathsOf(objects []MapObject) []*Path {
+	paths := make([]*Path, 0)
+	for _, object := range objects {
+		if p, ok := object.(*Path); ok {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// areasOf returns the Areas of the objects
+func areasOf(objects []MapObject) []*Area {
+	areas := make([]*Area, 0)
+	for _, object := range objects {
+		if a, ok := object.(*Area); ok {
+			areas = append(areas, a)
+		}
+	}
+	return areas
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"math"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+const (
+	// earthRadius is the mean earth radius in meters
+	earthRadius = 6371000.0
+
+	// geodesicSegments is the number of segments used to approximate a full geodesic ring
+	geodesicSegments = 180
+)
+
+// destinationPoint computes the point reached when travelling 'distance' meters from 'pos' along the great circle with the initial 'bearing' (radians, clockwise from north).
+func destinationPoint(pos s2.LatLng, bearing float64, distance float64) s2.LatLng {
+	th := distance / earthRadius
+	lat := pos.Lat.Radians()
+	lat1 := math.Asin(math.Sin(lat)*math.Cos(th) + math.Cos(lat)*math.Sin(th)*math.Cos(bearing))
+	lng1 := pos.Lng.Radians() +
+		math.Atan2(math.Sin(bearing)*math.Sin(th)*math.Cos(lat),
+			math.Cos(th)-math.Sin(lat)*math.Sin(lat1))
+	return s2.LatLng{
+		Lat: s1.Angle(lat1),
+		Lng: s1.Angle(lng1),
+	}.Normalized()
+}
+
+// geodesicRing computes a densified ring around 'center'; 'distance' returns the distance in meters for a given bearing (radians, clockwise from north).
+// The ring starts at bearing 'from' and ends at bearing 'to'; for a full turn the end point is omitted, since it equals the start point.
+func geodesicRing(center s2.LatLng, from, to float64, distance func(bearing float64) float64) []s2.LatLng {
+	segments := int(math.Ceil(geodesicSegments * math.Abs(to-from) / (2 * math.Pi)))
+	if segments < 1 {
+		segments = 1
+	}
+	last := segments
+	if math.Abs(to-from) >= 2*math.Pi {
+		last = segments - 1
+	}
+	ring := make([]s2.LatLng, 0, last+1)
+	for i := 0; i <= last; i++ {
+		bearing := from + (to-from)*float64(i)/float64(segments)
+		ring = append(ring, destinationPoint(center, bearing, distance(bearing)))
+	}
+	return ring
+}
+
+// ringBounds computes the bounding rect of a closed, clockwise ring; this includes the poles and the full longitude range if the ring encloses a pole.
+func ringBounds(ring []s2.LatLng) s2.Rect {
+	points := make([]s2.Point, 0, len(ring))
+	for i := len(ring) - 1; i >= 0; i-- {
+		p := s2.PointFromLatLng(ring[i])
+		if len(points) > 0 && points[len(points)-1] == p {
+			continue
+		}
+		points = append(points, p)
+	}
+	if len(points) < 3 {
+		r := s2.EmptyRect()
+		for _, ll := range ring {
+			r = r.AddPoint(ll)
+		}
+		return r
+	}
+	return s2.LoopFromPoints(points).RectBound()
+}
+
+// drawRing adds the closed ring to the current path of 'gc'; consecutive points are kept close to 'center' in x direction, such that rings crossing the antimeridian are not torn apart.
+func drawRing(gc Canvas, trans *Transformer, center s2.LatLng, ring []s2.LatLng) {
+	cx, _ := trans.LatLngToXY(center)
+	worldWidth := trans.numTiles * float64(trans.tileSize)
+	for _, ll := range ring {
+		x, y := trans.LatLngToXY(ll)
+		for x-cx > 0.5*worldWidth {
+			x -= worldWidth
+		}
+		for cx-x > 0.5*worldWidth {
+			x += worldWidth
+		}
+		gc.LineTo(x, y)
+	}
+	gc.ClosePath()
+}
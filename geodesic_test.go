@@ -0,0 +1,93 @@
+package sm
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestCircleBounds(t *testing.T) {
+	radius := 100000.0
+	circle := NewCircle(s2.LatLngFromDegrees(60.0, 10.0), color.Black, color.Transparent, radius, 1.0)
+	bounds := circle.Bounds()
+
+	// ~0.9° of latitude north and south of the center
+	dLat := radius / earthRadius * 180.0 / math.Pi
+	if math.Abs(bounds.Hi().Lat.Degrees()-(60.0+dLat)) > 0.01 || math.Abs(bounds.Lo().Lat.Degrees()-(60.0-dLat)) > 0.01 {
+		t.Errorf("unexpected latitude range: %v", bounds)
+	}
+
+	// at 60° latitude, the longitude extent is roughly twice the latitude extent
+	dLng := bounds.Hi().Lng.Degrees() - 10.0
+	if dLng < 1.9*dLat || dLng > 2.1*dLat {
+		t.Errorf("unexpected longitude range: %v", bounds)
+	}
+	if math.Abs((10.0-bounds.Lo().Lng.Degrees())-dLng) > 0.01 {
+		t.Errorf("asymmetric longitude range: %v", bounds)
+	}
+}
+
+func TestCircleBoundsAroundPole(t *testing.T) {
+	circle := NewCircle(s2.LatLngFromDegrees(89.0, 0.0), color.Black, color.Transparent, 500000.0, 1.0)
+	bounds := circle.Bounds()
+	if bounds.Hi().Lat.Degrees() < 89.999 {
+		t.Errorf("bounds do not include the north pole: %v", bounds)
+	}
+	if !bounds.Lng.IsFull() {
+		t.Errorf("bounds do not include the full longitude range: %v", bounds)
+	}
+}
+
+func TestSectorBounds(t *testing.T) {
+	center := s2.LatLngFromDegrees(0.0, 0.0)
+	sector := NewSector(center, color.Black, color.Transparent, 100000.0, 0.0, 90.0, 1.0)
+	bounds := sector.Bounds()
+	if bounds.Lo().Lat.Degrees() < -0.001 || bounds.Lo().Lng.Degrees() < -0.001 {
+		t.Errorf("north-east sector extends to the south or west: %v", bounds)
+	}
+	if !bounds.ContainsLatLng(center) {
+		t.Errorf("sector bounds do not contain the center: %v", bounds)
+	}
+}
+
+func TestEllipseBounds(t *testing.T) {
+	semiMajor, semiMinor := 200000.0, 50000.0
+	dMajor := semiMajor / earthRadius * 180.0 / math.Pi
+	dMinor := semiMinor / earthRadius * 180.0 / math.Pi
+	for _, test := range []struct {
+		rotation   float64
+		dLat, dLng float64
+	}{
+		{0.0, dMajor, dMinor},
+		{90.0, dMinor, dMajor},
+		// at 45°, the extents are sqrt((a²+b²)/2) in both directions
+		{45.0, math.Sqrt((dMajor*dMajor + dMinor*dMinor) / 2), math.Sqrt((dMajor*dMajor + dMinor*dMinor) / 2)},
+	} {
+		ellipse := NewEllipse(s2.LatLngFromDegrees(0.0, 10.0), color.Black, color.Transparent, semiMajor, semiMinor, test.rotation, 1.0)
+		bounds := ellipse.Bounds()
+		if math.Abs(bounds.Hi().Lat.Degrees()-test.dLat) > 0.01 || math.Abs(bounds.Lo().Lat.Degrees()+test.dLat) > 0.01 {
+			t.Errorf("rotation %v: unexpected latitude range: %v", test.rotation, bounds)
+		}
+		if math.Abs(bounds.Hi().Lng.Degrees()-10.0-test.dLng) > 0.01 || math.Abs(10.0-bounds.Lo().Lng.Degrees()-test.dLng) > 0.01 {
+			t.Errorf("rotation %v: unexpected longitude range: %v", test.rotation, bounds)
+		}
+	}
+}
+
+func TestEllipseBoundsAcrossAntimeridian(t *testing.T) {
+	ellipse := NewEllipse(s2.LatLngFromDegrees(0.0, 179.5), color.Black, color.Transparent, 200000.0, 50000.0, 90.0, 1.0)
+	bounds := ellipse.Bounds()
+	if !bounds.Lng.IsInverted() {
+		t.Fatalf("bounds do not cross the antimeridian: %v", bounds)
+	}
+	for _, lng := range []float64{179.0, 180.0, -179.0} {
+		if !bounds.ContainsLatLng(s2.LatLngFromDegrees(0.0, lng)) {
+			t.Errorf("bounds do not contain longitude %v: %v", lng, bounds)
+		}
+	}
+	if bounds.ContainsLatLng(s2.LatLngFromDegrees(0.0, 0.0)) || bounds.ContainsLatLng(s2.LatLngFromDegrees(0.0, -178.0)) {
+		t.Errorf("bounds are too wide: %v", bounds)
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"strconv"
+
+	"github.com/golang/geo/s2"
+)
+
+// geoJSONObject is any GeoJSON object, i.e. a FeatureCollection, a Feature, or a geometry
+type geoJSONObject struct {
+	Type        string                 `json:"type"`
+	Features    []geoJSONObject        `json:"features"`
+	Geometry    *geoJSONObject         `json:"geometry"`
+	Properties  map[string]interface{} `json:"properties"`
+	Geometries  []geoJSONObject        `json:"geometries"`
+	Coordinates json.RawMessage        `json:"coordinates"`
+}
+
+// LoadGeoJSON loads a GeoJSON file and converts it to map objects (see ParseGeoJSON).
+func LoadGeoJSON(fileName string, style *FeatureStyle) ([]MapObject, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGeoJSON(data, style)
+}
+
+// ParseGeoJSON converts a GeoJSON FeatureCollection, Feature, or geometry to map objects: Points and MultiPoints become
+// Markers (or Circles, if the feature has a numeric "radius" property in meters), LineStrings and MultiLineStrings
+// become Paths, and Polygons and MultiPolygons become Areas (with their holes); GeometryCollections are converted
+// recursively. Features are styled by style (nil selects NewFeatureStyle), overridden by their simplestyle-spec
+// properties; short "marker-symbol" values (up to two characters, e.g. "A" or "12") are used as marker labels.
+func ParseGeoJSON(data []byte, style *FeatureStyle) ([]MapObject, error) {
+	var root geoJSONObject
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if style == nil {
+		style = NewFeatureStyle()
+	}
+	objects := make([]MapObject, 0)
+	if err := root.convert(style, nil, &objects); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (o *geoJSONObject) convert(style *FeatureStyle, properties map[string]interface{}, objects *[]MapObject) error {
+	switch o.Type {
+	case "FeatureCollection":
+		for i := range o.Features {
+			if err := o.Features[i].convert(style, nil, objects); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "Feature":
+		if o.Geometry == nil {
+			return nil
+		}
+		return o.Geometry.convert(style, o.Properties, objects)
+	case "GeometryCollection":
+		for i := range o.Geometries {
+			if err := o.Geometries[i].convert(style, properties, objects); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	s, err := newGeoJSONFeatureStyle(style, properties)
+	if err != nil {
+		return err
+	}
+	return o.convertGeometry(s, objects)
+}
+
+func (o *geoJSONObject) convertGeometry(s *geoJSONFeatureStyle, objects *[]MapObject) error {
+	switch o.Type {
+	case "Point":
+		var c []float64
+		if err := o.decodeCoordinates(&c); err != nil {
+			return err
+		}
+		return s.addPoints([][]float64{c}, objects)
+	case "MultiPoint", "LineString":
+		var c [][]float64
+		if err := o.decodeCoordinates(&c); err != nil {
+			return err
+		}
+		if o.Type == "MultiPoint" {
+			return s.addPoints(c, objects)
+		}
+		return s.addLines([][][]float64{c}, objects)
+	case "MultiLineString", "Polygon":
+		var c [][][]float64
+		if err := o.decodeCoordinates(&c); err != nil {
+			return err
+		}
+		if o.Type == "MultiLineString" {
+			return s.addLines(c, objects)
+		}
+		return s.addPolygons([][][][]float64{c}, objects)
+	case "MultiPolygon":
+		var c [][][][]float64
+		if err := o.decodeCoordinates(&c); err != nil {
+			return err
+		}
+		return s.addPolygons(c, objects)
+	}
+	return fmt.Errorf("unsupported GeoJSON type: '%s'", o.Type)
+}
+
+func (o *geoJSONObject) decodeCoordinates(c interface{}) error {
+	if err := json.Unmarshal(o.Coordinates, c); err != nil {
+		return fmt.Errorf("bad GeoJSON %s coordinates: %v", o.Type, err)
+	}
+	return nil
+}
+
+// geoJSONFeatureStyle is the style of a single feature, i.e. the FeatureStyle with the feature's simplestyle-spec properties applied
+type geoJSONFeatureStyle struct {
+	FeatureStyle
+	label  string
+	radius float64
+}
+
+func newGeoJSONFeatureStyle(style *FeatureStyle, properties map[string]interface{}) (*geoJSONFeatureStyle, error) {
+	s := &geoJSONFeatureStyle{FeatureStyle: *style}
+	var err error
+	if s.Stroke, err = geoJSONColor(properties, "stroke", "stroke-opacity", 1.0, s.Stroke); err != nil {
+		return nil, err
+	}
+	// simplestyle-spec's default fill opacity is 0.6
+	if s.Fill, err = geoJSONColor(properties, "fill", "fill-opacity", 0.6, s.Fill); err != nil {
+		return nil, err
+	}
+	if s.MarkerColor, err = geoJSONColor(properties, "marker-color", "", 1.0, s.MarkerColor); err != nil {
+		return nil, err
+	}
+	if width, ok := geoJSONNumber(properties, "stroke-width"); ok {
+		s.StrokeWidth = width
+	}
+	if radius, ok := geoJSONNumber(properties, "radius"); ok {
+		s.radius = radius
+	}
+	switch properties["marker-size"] {
+	case "small":
+		s.MarkerSize = 12.0
+	case "medium":
+		s.MarkerSize = 16.0
+	case "large":
+		s.MarkerSize = 24.0
+	}
+	if symbol, ok := properties["marker-symbol"]; ok {
+		s.label = markerLabel(fmt.Sprint(symbol))
+	}
+	return s, nil
+}
+
+// geoJSONColor returns the color property with the opacity property applied; defaultOpacity applies to opaque color
+// properties without opacity property
+func geoJSONColor(properties map[string]interface{}, name string, opacityName string, defaultOpacity float64, def color.Color) (color.Color, error) {
+	col := def
+	value, hasColor := properties[name].(string)
+	if hasColor {
+		var err error
+		if col, err = ParseColorString(value); err != nil {
+			return nil, err
+		}
+	}
+	if opacity, ok := geoJSONNumber(properties, opacityName); ok {
+		col = withOpacity(col, opacity)
+	} else if _, _, _, a := col.RGBA(); hasColor && a == 0xffff && defaultOpacity < 1.0 {
+		col = withOpacity(col, defaultOpacity)
+	}
+	return col, nil
+}
+
+// geoJSONNumber returns the numeric property (given as number or string)
+func geoJSONNumber(properties map[string]interface{}, name string) (float64, bool) {
+	switch value := properties[name].(type) {
+	case float64:
+		return value, true
+	case string:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+func (s *geoJSONFeatureStyle) addPoints(coordinates [][]float64, objects *[]MapObject) error {
+	for _, c := range coordinates {
+		pos, err := geoJSONLatLng(c)
+		if err != nil {
+			return err
+		}
+		if s.radius > 0 {
+			*objects = append(*objects, NewCircle(pos, s.Stroke, s.Fill, s.radius, s.StrokeWidth))
+			continue
+		}
+		marker := NewMarker(pos, s.MarkerColor, s.MarkerSize)
+		marker.Label = s.label
+		*objects = append(*objects, marker)
+	}
+	return nil
+}
+
+func (s *geoJSONFeatureStyle) addLines(coordinates [][][]float64, objects *[]MapObject) error {
+	for _, c := range coordinates {
+		positions, err := geoJSONLatLngs(c)
+		if err != nil {
+			return err
+		}
+		*objects = append(*objects, NewPath(positions, s.Stroke, s.StrokeWidth))
+	}
+	return nil
+}
+
+func (s *geoJSONFeatureStyle) addPolygons(coordinates [][][][]float64, objects *[]MapObject) error {
+	for _, polygon := range coordinates {
+		if len(polygon) == 0 {
+			continue
+		}
+		rings := make([][]s2.LatLng, 0, len(polygon))
+		for _, ring := range polygon {
+			positions, err := geoJSONLatLngs(ring)
+			if err != nil {
+				return err
+			}
+			rings = append(rings, positions)
+		}
+		*objects = append(*objects, newRingsArea(rings, s.Stroke, s.Fill, s.StrokeWidth))
+	}
+	return nil
+}
+
+// geoJSONLatLng converts a GeoJSON position, i.e. [longitude, latitude(, elevation)]
+func geoJSONLatLng(c []float64) (s2.LatLng, error) {
+	if len(c) < 2 {
+		return s2.LatLng{}, fmt.Errorf("bad GeoJSON position: %v", c)
+	}
+	return s2.LatLngFromDegrees(c[1], c[0]), nil
+}
+
+func geoJSONLatLngs(coordinates [][]float64) ([]s2.LatLng, error) {
+	positions := make([]s2.LatLng, 0, len(coordinates))
+	for _, c := range coordinates {
+		pos, err := geoJSONLatLng(c)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, pos)
+	}
+	return positions, nil
+}
+
+// loadGeoJSONPaths loads the LineStrings and MultiLineStrings of a GeoJSON file as Paths for the 'geojson:' path token
+func loadGeoJSONPaths(fileName string, col color.Color, weight float64) ([]*Path, error) {
+	style := NewFeatureStyle()
+	style.Stroke = col
+	style.StrokeWidth = weight
+	objects, err := LoadGeoJSON(fileName, style)
+	return pathsOf(objects), err
+}
+
+// loadGeoJSONAreas loads the Polygons and MultiPolygons of a GeoJSON file as Areas for the 'geojson:' area token
+func loadGeoJSONAreas(fileName string, col color.Color, fill color.Color, weight float64) ([]*Area, error) {
+	style := NewFeatureStyle()
+	style.Stroke = col
+	style.Fill = fill
+	style.StrokeWidth = weight
+	objects, err := LoadGeoJSON(fileName, style)
+	return areasOf(objects), err
+}
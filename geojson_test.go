@@ -0,0 +1,120 @@
+package sm
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/geo/s2"
+)
+
+const testGeoJSON = `{
+  "type": "FeatureCollection",
+  "features": [
+    {"type": "Feature", "properties": {"marker-color": "#0000ff", "marker-size": "large", "marker-symbol": "A"},
+     "geometry": {"type": "Point", "coordinates": [13.4, 52.5]}},
+    {"type": "Feature", "properties": {"radius": 250, "stroke": "#00ff00"},
+     "geometry": {"type": "Point", "coordinates": [13.5, 52.6]}},
+    {"type": "Feature", "properties": {"stroke": "red", "stroke-width": 3},
+     "geometry": {"type": "MultiLineString", "coordinates": [[[13.4, 52.5], [13.5, 52.6]], [[13.6, 52.7], [13.7, 52.8]]]}},
+    {"type": "Feature", "properties": {"fill": "#ff0000", "fill-opacity": 0.5},
+     "geometry": {"type": "GeometryCollection", "geometries": [
+       {"type": "Polygon", "coordinates": [[[13.0, 52.0], [14.0, 52.0], [14.0, 53.0], [13.0, 52.0]], [[13.5, 52.2], [13.8, 52.2], [13.8, 52.5], [13.5, 52.2]]]},
+       {"type": "MultiPoint", "coordinates": [[13.1, 52.1], [13.2, 52.2, 100.0]]}
+     ]}},
+    {"type": "Feature", "properties": null, "geometry": null}
+  ]
+}`
+
+func parseTestGeoJSON(t *testing.T) []MapObject {
+	objects, err := ParseGeoJSON([]byte(testGeoJSON), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 7 {
+		t.Fatalf("unexpected number of objects: %d", len(objects))
+	}
+	return objects
+}
+
+func TestParseGeoJSONPoints(t *testing.T) {
+	objects := parseTestGeoJSON(t)
+	marker, ok := objects[0].(*Marker)
+	if !ok || marker.Position != s2.LatLngFromDegrees(52.5, 13.4) || marker.Size != 24.0 || marker.Label != "A" || marker.Color != (color.RGBA{0, 0, 0xff, 0xff}) {
+		t.Errorf("unexpected marker: %v", objects[0])
+	}
+	if circle, ok := objects[1].(*Circle); !ok || circle.Radius != 250 || circle.Color != (color.RGBA{0, 0xff, 0, 0xff}) {
+		t.Errorf("unexpected circle: %v", objects[1])
+	}
+	for _, object := range objects[5:] {
+		if marker, ok := object.(*Marker); !ok || marker.Color != (color.RGBA{0x7e, 0x7e, 0x7e, 0xff}) {
+			t.Errorf("unexpected marker: %v", object)
+		}
+	}
+}
+
+func TestParseGeoJSONLinesAndPolygons(t *testing.T) {
+	objects := parseTestGeoJSON(t)
+	for _, object := range objects[2:4] {
+		if path, ok := object.(*Path); !ok || len(path.Positions) != 2 || path.Weight != 3 {
+			t.Errorf("unexpected path: %v", object)
+		}
+	}
+	area, ok := objects[4].(*Area)
+	if !ok || len(area.Positions) != 3 || len(area.Rings) != 1 || len(area.Rings[0]) != 3 || area.FillRule != gg.FillRuleEvenOdd || area.Fill != (color.NRGBA{0xff, 0, 0, 0x80}) || area.Weight != 2 {
+		t.Errorf("unexpected area: %v", objects[4])
+	}
+
+	if _, err := ParseGeoJSON([]byte(`{"type": "Polygon", "coordinates": [1, 2]}`), nil); err == nil {
+		t.Error("error expected for bad coordinates")
+	}
+}
+
+func TestParseAreasStringGeoJSON(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.geojson")
+	if err := os.WriteFile(fileName, []byte(testGeoJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	areas, err := ParseAreasString("color:blue|weight:1|geojson:" + fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(areas) != 1 || areas[0].Color != (color.RGBA{0, 0, 0xff, 0xff}) || areas[0].Weight != 1 {
+		t.Errorf("unexpected areas: %v", areas)
+	}
+	if _, err := ParseAreaString("geojson:" + fileName + "|1,2|3,4|1,4"); err == nil {
+		t.Error("error expected for multiple areas")
+	}
+
+	paths, err := ParsePathString("geojson:" + fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("unexpected number of paths: %d", len(paths))
+	}
+}
+
+func TestParseGeoJSONFillOpacity(t *testing.T) {
+	for _, test := range []struct {
+		properties string
+		fill       color.Color
+	}{
+		{`{"fill": "#ff0000"}`, color.NRGBA{0xff, 0, 0, 0x99}},
+		{`{"fill": "#ff0000", "fill-opacity": 1}`, color.NRGBA{0xff, 0, 0, 0xff}},
+		{`{"fill": "0xff000080"}`, color.RGBA{0xff, 0, 0, 0x80}},
+		{`{}`, NewFeatureStyle().Fill},
+	} {
+		objects, err := ParseGeoJSON([]byte(`{"type": "Feature", "properties": `+test.properties+`,
+			"geometry": {"type": "Polygon", "coordinates": [[[13.0, 52.0], [14.0, 52.0], [14.0, 53.0], [13.0, 52.0]]]}}`), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if area, ok := objects[0].(*Area); !ok || area.Fill != test.fill {
+			t.Errorf("%s: unexpected fill: %v", test.properties, objects[0])
+		}
+	}
+}
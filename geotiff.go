@@ -0,0 +1,174 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+)
+
+// TIFF field types
+const (
+	tiffShort  = 3
+	tiffLong   = 4
+	tiffDouble = 12
+)
+
+// tiffEntry is a single IFD entry; values are either []uint16, []uint32, or []float64
+type tiffEntry struct {
+	tag    uint16
+	values interface{}
+}
+
+func (e tiffEntry) typeAndCount() (uint16, int) {
+	switch v := e.values.(type) {
+	case []uint16:
+		return tiffShort, len(v)
+	case []uint32:
+		return tiffLong, len(v)
+	case []float64:
+		return tiffDouble, len(v)
+	}
+	return 0, 0
+}
+
+// RenderGeoTIFF renders the map image including all map objects and writes it as GeoTIFF file to w.
+func (m *Context) RenderGeoTIFF(w io.Writer) error {
+	img, trans, err := m.RenderWithOptions(RenderOptions{})
+	if err != nil {
+		return err
+	}
+	return EncodeGeoTIFF(w, img, trans)
+}
+
+// EncodeGeoTIFF writes img as deflate compressed RGBA GeoTIFF file to w; the georeferencing (EPSG:3857 model tie point and
+// pixel scale) is derived from trans, which has to be the Transformer returned together with img (e.g. by RenderWithOptions).
+func EncodeGeoTIFF(w io.Writer, img image.Image, trans *Transformer) error {
+	b := img.Bounds()
+	data, err := compressTIFFStrip(img, b)
+	if err != nil {
+		return err
+	}
+
+	// layout: header, image data, IFD (word aligned) with out-of-line values
+	const headerSize = 8
+	ifdOffset := headerSize + len(data) + len(data)%2
+	entries := geoTIFFEntries(b.Dx(), b.Dy(), b.Dy(), []uint32{headerSize}, []uint32{uint32(len(data))}, trans)
+
+	var out bytes.Buffer
+	writeTIFFHeader(&out, uint32(ifdOffset))
+	out.Write(data)
+	if len(data)%2 != 0 {
+		out.WriteByte(0)
+	}
+	out.Write(encodeTIFFIFD(ifdOffset, entries))
+
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+// compressTIFFStrip returns the deflate compressed non-premultiplied RGBA pixels of the rectangle r of img
+func compressTIFFStrip(img image.Image, r image.Rectangle) ([]byte, error) {
+	var data bytes.Buffer
+	z := zlib.NewWriter(&data)
+	row := make([]byte, 4*r.Dx())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			i := 4 * (x - r.Min.X)
+			row[i], row[i+1], row[i+2], row[i+3] = c.R, c.G, c.B, c.A
+		}
+		if _, err := z.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := z.Close(); err != nil {
+		return nil, err
+	}
+	return data.Bytes(), nil
+}
+
+func writeTIFFHeader(w io.Writer, ifdOffset uint32) {
+	le := binary.LittleEndian
+	io.WriteString(w, "II")
+	binary.Write(w, le, uint16(42))
+	binary.Write(w, le, ifdOffset)
+}
+
+// geoTIFFEntries returns the IFD entries of a deflate compressed RGBA GeoTIFF image with the given strips
+func geoTIFFEntries(width, height, rowsPerStrip int, stripOffsets, stripByteCounts []uint32, trans *Transformer) []tiffEntry {
+	mx, my := trans.XYToWebMercator(0, 0)
+	scale := trans.PixelSize()
+	geoKeys := []uint16{
+		1, 1, 0, 3, // version, revision, minor revision, number of keys
+		1024, 0, 1, 1, // GTModelTypeGeoKey: projected
+		1025, 0, 1, 1, // GTRasterTypeGeoKey: pixel is area
+		3072, 0, 1, 3857, // ProjectedCSTypeGeoKey: EPSG:3857
+	}
+	// entries have to be sorted by tag
+	return []tiffEntry{
+		{256, []uint32{uint32(width)}},         // ImageWidth
+		{257, []uint32{uint32(height)}},        // ImageLength
+		{258, []uint16{8, 8, 8, 8}},            // BitsPerSample
+		{259, []uint16{8}},                     // Compression: deflate
+		{262, []uint16{2}},                     // PhotometricInterpretation: RGB
+		{273, stripOffsets},                    // StripOffsets
+		{277, []uint16{4}},                     // SamplesPerPixel
+		{278, []uint32{uint32(rowsPerStrip)}},  // RowsPerStrip
+		{279, stripByteCounts},                 // StripByteCounts
+		{284, []uint16{1}},                     // PlanarConfiguration: chunky
+		{338, []uint16{2}},                     // ExtraSamples: unassociated alpha
+		{33550, []float64{scale, scale, 0}},    // ModelPixelScaleTag
+		{33922, []float64{0, 0, 0, mx, my, 0}}, // ModelTiepointTag
+		{34735, geoKeys},                       // GeoKeyDirectoryTag
+	}
+}
+
+// encodeTIFFIFD encodes the IFD, which is located at ifdOffset within the file, followed by the out-of-line values
+func encodeTIFFIFD(ifdOffset int, entries []tiffEntry) []byte {
+	ifdSize := 2 + 12*len(entries) + 4
+	le := binary.LittleEndian
+
+	var out, values bytes.Buffer
+	binary.Write(&out, le, uint16(len(entries)))
+	for _, e := range entries {
+		typ, count := e.typeAndCount()
+		binary.Write(&out, le, e.tag)
+		binary.Write(&out, le, typ)
+		binary.Write(&out, le, uint32(count))
+		if tiffValuesSize(e) > 4 {
+			binary.Write(&out, le, uint32(ifdOffset+ifdSize+values.Len()))
+			binary.Write(&values, le, e.values)
+		} else {
+			var field [4]byte
+			var buf bytes.Buffer
+			binary.Write(&buf, le, e.values)
+			copy(field[:], buf.Bytes())
+			out.Write(field[:])
+		}
+	}
+	binary.Write(&out, le, uint32(0)) // no further IFDs
+	out.Write(values.Bytes())
+	return out.Bytes()
+}
+
+// tiffValuesSize returns the size of the entry's values in bytes
+func tiffValuesSize(e tiffEntry) int {
+	typ, count := e.typeAndCount()
+	switch typ {
+	case tiffShort:
+		return 2 * count
+	case tiffLong:
+		return 4 * count
+	case tiffDouble:
+		return 8 * count
+	}
+	return 0
+}
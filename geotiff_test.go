@@ -0,0 +1,51 @@
+package sm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"golang.org/x/image/tiff"
+)
+
+func TestEncodeGeoTIFF(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetSize(256, 128)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.SetCenter(s2.LatLngFromDegrees(0, 0))
+	ctx.SetZoom(0)
+
+	var buf bytes.Buffer
+	if err := ctx.RenderGeoTIFF(&buf); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	img, err := tiff.Decode(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	if img.Bounds().Dx() != 256 || img.Bounds().Dy() != 128 {
+		t.Errorf("unexpected image size: %v", img.Bounds())
+	}
+
+	// the tie point follows the pixel scale in the out-of-line values
+	data := buf.Bytes()
+	const tiePointTag = 33922
+	ifd := int(binary.LittleEndian.Uint32(data[4:]))
+	for i := ifd + 2; i+12 <= len(data); i += 12 {
+		if binary.LittleEndian.Uint16(data[i:]) != tiePointTag {
+			continue
+		}
+		offset := binary.LittleEndian.Uint32(data[i+8:])
+		x := math.Float64frombits(binary.LittleEndian.Uint64(data[offset+24:]))
+		y := math.Float64frombits(binary.LittleEndian.Uint64(data[offset+32:]))
+		// the image covers the full world width and half of its height
+		if math.Abs(x+20037508.34) > 0.01 || math.Abs(y-10018754.17) > 0.01 {
+			t.Errorf("unexpected tie point: %f %f", x, y)
+		}
+		return
+	}
+	t.Errorf("missing tie point")
+}
@@ -12,6 +12,7 @@ require (
 	github.com/mazznoer/csscolorparser v0.1.8
 	github.com/tkrajina/gpxgo v1.4.0
 	golang.org/x/image v0.28.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,245 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+// GPXPoint is a point of a GPX waypoint, route, or track segment with its optional elevation, timestamp, and speed
+type GPXPoint struct {
+	Position s2.LatLng
+	// Elevation is the elevation in meters; only valid if HasElevation is true
+	Elevation    float64
+	HasElevation bool
+	// Time is the timestamp; zero if missing
+	Time time.Time
+	// Speed is the speed in meters per second, derived from the distance and time to the previous point (or, for the
+	// first point, to the next point); only valid if HasSpeed is true
+	Speed    float64
+	HasSpeed bool
+}
+
+// GPXWaypoint is a named waypoint of a GPX file
+type GPXWaypoint struct {
+	GPXPoint
+	Name string
+}
+
+// GPXRoute is a named route of a GPX file
+type GPXRoute struct {
+	Name   string
+	Points []GPXPoint
+}
+
+// GPXTrack is a named track of a GPX file consisting of one or more segments
+type GPXTrack struct {
+	Name     string
+	Segments [][]GPXPoint
+}
+
+// GPXData contains the waypoints, routes, and tracks of a GPX file
+type GPXData struct {
+	Waypoints []GPXWaypoint
+	Routes    []GPXRoute
+	Tracks    []GPXTrack
+}
+
+// LoadGPX loads the waypoints, routes, and tracks of a GPX file.
+func LoadGPX(fileName string) (*GPXData, error) {
+	gpxData, err := gpx.ParseFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return newGPXData(gpxData), nil
+}
+
+// ParseGPX parses the waypoints, routes, and tracks of a GPX document.
+func ParseGPX(data []byte) (*GPXData, error) {
+	gpxData, err := gpx.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return newGPXData(gpxData), nil
+}
+
+func newGPXData(gpxData *gpx.GPX) *GPXData {
+	d := &GPXData{
+		Waypoints: make([]GPXWaypoint, 0, len(gpxData.Waypoints)),
+		Routes:    make([]GPXRoute, 0, len(gpxData.Routes)),
+		Tracks:    make([]GPXTrack, 0, len(gpxData.Tracks)),
+	}
+	for i := range gpxData.Waypoints {
+		pt := &gpxData.Waypoints[i]
+		d.Waypoints = append(d.Waypoints, GPXWaypoint{newGPXPoint(pt), pt.Name})
+	}
+	for _, rte := range gpxData.Routes {
+		d.Routes = append(d.Routes, GPXRoute{rte.Name, newGPXPoints(rte.Points)})
+	}
+	for _, trk := range gpxData.Tracks {
+		track := GPXTrack{Name: trk.Name, Segments: make([][]GPXPoint, 0, len(trk.Segments))}
+		for _, seg := range trk.Segments {
+			track.Segments = append(track.Segments, newGPXPoints(seg.Points))
+		}
+		d.Tracks = append(d.Tracks, track)
+	}
+	return d
+}
+
+func newGPXPoint(pt *gpx.GPXPoint) GPXPoint {
+	return GPXPoint{
+		Position:     s2.LatLngFromDegrees(pt.GetLatitude(), pt.GetLongitude()),
+		Elevation:    pt.Elevation.Value(),
+		HasElevation: pt.Elevation.NotNull(),
+		Time:         pt.Timestamp,
+	}
+}
+
+// newGPXPoints converts the points, and derives their speeds from consecutive timestamps
+func newGPXPoints(gpxPoints []gpx.GPXPoint) []GPXPoint {
+	points := make([]GPXPoint, 0, len(gpxPoints))
+	for i := range gpxPoints {
+		points = append(points, newGPXPoint(&gpxPoints[i]))
+	}
+	for i := 1; i < len(points); i++ {
+		prev, pt := &points[i-1], &points[i]
+		if prev.Time.IsZero() || pt.Time.IsZero() || !pt.Time.After(prev.Time) {
+			continue
+		}
+		pt.Speed = prev.Position.Distance(pt.Position).Radians() * earthRadius / pt.Time.Sub(prev.Time).Seconds()
+		pt.HasSpeed = true
+		if i == 1 {
+			prev.Speed, prev.HasSpeed = pt.Speed, true
+		}
+	}
+	return points
+}
+
+// SelectTracks returns the tracks matching any of the selectors, which are either track names or 1-based track
+// indices; all tracks are returned if there are no selectors. Selectors matching no track are an error.
+func (d *GPXData) SelectTracks(selectors []string) ([]GPXTrack, error) {
+	if len(selectors) == 0 {
+		return d.Tracks, nil
+	}
+	selected := make([]bool, len(d.Tracks))
+	for _, selector := range selectors {
+		found := false
+		index, err := strconv.Atoi(selector)
+		for i, track := range d.Tracks {
+			if track.Name == selector || (err == nil && index == i+1) {
+				selected[i] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no GPX track matches '%s'", selector)
+		}
+	}
+	tracks := make([]GPXTrack, 0)
+	for i, track := range d.Tracks {
+		if selected[i] {
+			tracks = append(tracks, track)
+		}
+	}
+	return tracks, nil
+}
+
+// MapObjects converts the GPX data to map objects: waypoints become Markers labeled with their names, and routes and
+// track segments become Paths; the objects are styled by style (nil selects NewFeatureStyle).
+func (d *GPXData) MapObjects(style *FeatureStyle) []MapObject {
+	if style == nil {
+		style = NewFeatureStyle()
+	}
+	objects := make([]MapObject, 0)
+	for _, marker := range d.markers(style.MarkerColor, style.MarkerSize) {
+		objects = append(objects, marker)
+	}
+	for _, path := range d.paths(d.Tracks, style.Stroke, style.StrokeWidth) {
+		objects = append(objects, path)
+	}
+	return objects
+}
+
+func (d *GPXData) markers(col color.Color, size float64) []*Marker {
+	markers := make([]*Marker, 0, len(d.Waypoints))
+	for _, wpt := range d.Waypoints {
+		marker := NewMarker(wpt.Position, col, size)
+		marker.Label = wpt.Name
+		markers = append(markers, marker)
+	}
+	return markers
+}
+
+// paths returns the paths of the routes and of the given tracks
+func (d *GPXData) paths(tracks []GPXTrack, col color.Color, weight float64) []*Path {
+	paths := make([]*Path, 0)
+	for _, rte := range d.Routes {
+		if len(rte.Points) > 0 {
+			paths = append(paths, NewPath(gpxPositions(rte.Points), col, weight))
+		}
+	}
+	for _, trk := range tracks {
+		for _, seg := range trk.Segments {
+			if len(seg) > 0 {
+				paths = append(paths, NewPath(gpxPositions(seg), col, weight))
+			}
+		}
+	}
+	return paths
+}
+
+func gpxPositions(points []GPXPoint) []s2.LatLng {
+	positions := make([]s2.LatLng, 0, len(points))
+	for _, pt := range points {
+		positions = append(positions, pt.Position)
+	}
+	return positions
+}
+
+// NewStyledPaths creates paths from the points, where the color of the line between consecutive points is determined by
+// style (e.g. from the points' elevations or speeds, see InterpolateColor); consecutive lines of the same color are
+// merged into a single path.
+func NewStyledPaths(points []GPXPoint, weight float64, style func(from, to GPXPoint) color.Color) []*Path {
+	paths := make([]*Path, 0)
+	var current *Path
+	for i := 1; i < len(points); i++ {
+		col := style(points[i-1], points[i])
+		if current == nil || current.Color != col {
+			current = NewPath([]s2.LatLng{points[i-1].Position}, col, weight)
+			paths = append(paths, current)
+		}
+		current.Positions = append(current.Positions, points[i].Position)
+	}
+	return paths
+}
+
+// loadGPXMarkers loads the waypoints of a GPX file as Markers for the 'gpx:' marker token
+func loadGPXMarkers(fileName string, col color.Color, size float64) ([]*Marker, error) {
+	d, err := LoadGPX(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return d.markers(col, size), nil
+}
+
+// loadGPXPaths loads the routes and the selected tracks of a GPX file as Paths for the 'gpx:' path token
+func loadGPXPaths(fileName string, selectors []string, col color.Color, weight float64) ([]*Path, error) {
+	d, err := LoadGPX(fileName)
+	if err != nil {
+		return nil, err
+	}
+	tracks, err := d.SelectTracks(selectors)
+	if err != nil {
+		return nil, err
+	}
+	return d.paths(tracks, col, weight), nil
+}
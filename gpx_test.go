@@ -0,0 +1,111 @@
+package sm
+
+import (
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="52.5" lon="13.4"><name>Start</name></wpt>
+  <rte><name>Plan</name><rtept lat="52.5" lon="13.4"/><rtept lat="52.6" lon="13.5"/></rte>
+  <trk><name>Morning</name>
+    <trkseg>
+      <trkpt lat="52.5" lon="13.4"><ele>30</ele><time>2026-05-01T08:00:00Z</time></trkpt>
+      <trkpt lat="52.501" lon="13.4"><ele>40</ele><time>2026-05-01T08:00:10Z</time></trkpt>
+      <trkpt lat="52.502" lon="13.4"><time>2026-05-01T08:00:30Z</time></trkpt>
+    </trkseg>
+    <trkseg><trkpt lat="52.6" lon="13.5"/><trkpt lat="52.7" lon="13.6"/></trkseg>
+  </trk>
+  <trk><name>Evening</name><trkseg><trkpt lat="48.1" lon="11.5"/><trkpt lat="48.2" lon="11.6"/></trkseg></trk>
+</gpx>`
+
+func TestParseGPX(t *testing.T) {
+	d, err := ParseGPX([]byte(testGPX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Waypoints) != 1 || d.Waypoints[0].Name != "Start" || len(d.Routes) != 1 || len(d.Tracks) != 2 {
+		t.Fatalf("unexpected GPX data: %v", d)
+	}
+
+	points := d.Tracks[0].Segments[0]
+	if !points[0].HasElevation || points[0].Elevation != 30 || points[2].HasElevation {
+		t.Errorf("unexpected elevations: %v", points)
+	}
+	// 0.001° latitude ~ 111.2m
+	if !points[1].HasSpeed || math.Abs(points[1].Speed-11.12) > 0.01 || points[0].Speed != points[1].Speed || math.Abs(points[2].Speed-5.56) > 0.01 {
+		t.Errorf("unexpected speeds: %v", points)
+	}
+	if d.Tracks[0].Segments[1][1].HasSpeed {
+		t.Error("unexpected speed without timestamps")
+	}
+}
+
+func TestGPXSelectTracks(t *testing.T) {
+	d, err := ParseGPX([]byte(testGPX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tracks, err := d.SelectTracks([]string{"Evening"}); err != nil || len(tracks) != 1 || tracks[0].Name != "Evening" {
+		t.Errorf("unexpected tracks: %v, %v", tracks, err)
+	}
+	if tracks, err := d.SelectTracks([]string{"2", "1"}); err != nil || len(tracks) != 2 || tracks[0].Name != "Morning" {
+		t.Errorf("unexpected tracks: %v, %v", tracks, err)
+	}
+	if _, err := d.SelectTracks([]string{"3"}); err == nil {
+		t.Error("error expected for missing track")
+	}
+
+	objects := d.MapObjects(nil)
+	if len(objects) != 5 {
+		t.Errorf("unexpected number of objects: %d", len(objects))
+	}
+	if marker, ok := objects[0].(*Marker); !ok || marker.Label != "Start" {
+		t.Errorf("unexpected marker: %v", objects[0])
+	}
+}
+
+func TestNewStyledPaths(t *testing.T) {
+	d, err := ParseGPX([]byte(testGPX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast := color.RGBA{0xff, 0, 0, 0xff}
+	slow := color.RGBA{0, 0, 0xff, 0xff}
+	paths := NewStyledPaths(d.Tracks[0].Segments[0], 3, func(from, to GPXPoint) color.Color {
+		return InterpolateColor(slow, fast, (to.Speed-5)/5)
+	})
+	if len(paths) != 2 || len(paths[0].Positions) != 2 || paths[0].Color != (color.RGBA64{0xffff, 0, 0, 0xffff}) {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+}
+
+func TestParsePathStringGPX(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.gpx")
+	if err := os.WriteFile(fileName, []byte(testGPX), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := ParsePathString("weight:2|track:Morning|gpx:" + fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 3 || paths[0].Weight != 2 {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+	if _, err := ParsePathString("track:Night|gpx:" + fileName); err == nil {
+		t.Error("error expected for missing track")
+	}
+
+	markers, err := ParseMarkerString("color:blue|gpx:" + fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(markers) != 1 || markers[0].Label != "Start" {
+		t.Errorf("unexpected markers: %v", markers)
+	}
+}
@@ -72,6 +72,12 @@ func ParseImageMarkerString(s string) ([]*ImageMarker, error) {
 			if err != nil {
 				return nil, err
 			}
+		} else if ok, suffix := hasPrefix(ss, "kml:"); ok {
+			kmlMarkers, err := loadKMLImageMarkers(suffix)
+			if err != nil {
+				return nil, err
+			}
+			markers = append(markers, kmlMarkers...)
 		} else {
 			lat, lng, err := coordsparser.Parse(ss)
 			if err != nil {
@@ -117,6 +123,11 @@ func (m *ImageMarker) Bounds() s2.Rect {
 
 // Draw draws the object in the given graphical context.
 func (m *ImageMarker) Draw(gc *gg.Context, trans *Transformer) {
+	m.DrawCanvas(gc, trans)
+}
+
+// DrawCanvas draws the object to the given canvas.
+func (m *ImageMarker) DrawCanvas(gc Canvas, trans *Transformer) {
 	if !CanDisplay(m.Position) {
 		log.Printf("ImageMarker coordinates not displayable: %f/%f", m.Position.Lat.Degrees(), m.Position.Lng.Degrees())
 		return
@@ -0,0 +1,546 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"golang.org/x/image/draw"
+)
+
+// kmlContainer is a KML document, folder, or the root element
+type kmlContainer struct {
+	Styles     []kmlStyle     `xml:"Style"`
+	StyleMaps  []kmlStyleMap  `xml:"StyleMap"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+	Documents  []kmlContainer `xml:"Document"`
+	Folders    []kmlContainer `xml:"Folder"`
+}
+
+type kmlStyle struct {
+	ID        string        `xml:"id,attr"`
+	IconStyle *kmlIconStyle `xml:"IconStyle"`
+	LineStyle *kmlLineStyle `xml:"LineStyle"`
+	PolyStyle *kmlPolyStyle `xml:"PolyStyle"`
+}
+
+type kmlIconStyle struct {
+	Color   string      `xml:"color"`
+	Scale   *float64    `xml:"scale"`
+	Href    string      `xml:"Icon>href"`
+	HotSpot *kmlHotSpot `xml:"hotSpot"`
+}
+
+type kmlHotSpot struct {
+	X      float64 `xml:"x,attr"`
+	Y      float64 `xml:"y,attr"`
+	XUnits string  `xml:"xunits,attr"`
+	YUnits string  `xml:"yunits,attr"`
+}
+
+type kmlLineStyle struct {
+	Color string   `xml:"color"`
+	Width *float64 `xml:"width"`
+}
+
+type kmlPolyStyle struct {
+	Color   string `xml:"color"`
+	Fill    *int   `xml:"fill"`
+	Outline *int   `xml:"outline"`
+}
+
+type kmlStyleMap struct {
+	ID    string `xml:"id,attr"`
+	Pairs []struct {
+		Key      string `xml:"key"`
+		StyleURL string `xml:"styleUrl"`
+	} `xml:"Pair"`
+}
+
+type kmlPlacemark struct {
+	Name     string    `xml:"name"`
+	StyleURL string    `xml:"styleUrl"`
+	Style    *kmlStyle `xml:"Style"`
+	kmlGeometry
+}
+
+type kmlGeometry struct {
+	Points          []kmlCoordinates `xml:"Point"`
+	LineStrings     []kmlCoordinates `xml:"LineString"`
+	LinearRings     []kmlCoordinates `xml:"LinearRing"`
+	Polygons        []kmlPolygon     `xml:"Polygon"`
+	MultiGeometries []kmlGeometry    `xml:"MultiGeometry"`
+}
+
+type kmlCoordinates struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlPolygon struct {
+	Outer kmlCoordinates   `xml:"outerBoundaryIs>LinearRing"`
+	Inner []kmlCoordinates `xml:"innerBoundaryIs>LinearRing"`
+}
+
+// kmlParser converts placemarks to map objects
+type kmlParser struct {
+	style     *FeatureStyle
+	styles    map[string]*kmlStyle
+	styleMaps map[string]*kmlStyleMap
+	// loadIcon loads the icon image referenced by href; icons are not loaded, if it is nil
+	loadIcon KMLIconLoader
+	icons    map[string]image.Image
+}
+
+// LoadKML loads a KML or KMZ file and converts its placemarks to map objects (see ParseKML); icons are loaded from the KMZ
+// archive, relative to the KML file, or from http(s) URLs.
+func LoadKML(fileName string, style *FeatureStyle) ([]MapObject, error) {
+	return loadKML(fileName, style, true)
+}
+
+func loadKML(fileName string, style *FeatureStyle, loadIcons bool) ([]MapObject, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(fileName)
+	loadIconFile := func(href string) (image.Image, error) {
+		if !isKMLIconURL(href) && !filepath.IsAbs(href) {
+			href = filepath.Join(dir, filepath.FromSlash(href))
+		}
+		return LoadKMLIcon(href)
+	}
+	loadIcon := KMLIconLoader(loadIconFile)
+	if bytes.HasPrefix(data, []byte("PK")) {
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = readKMZDocument(archive); err != nil {
+			return nil, err
+		}
+		loadIcon = func(href string) (image.Image, error) {
+			return loadKMZIcon(archive, href, loadIconFile)
+		}
+	}
+	if !loadIcons {
+		loadIcon = nil
+	}
+	return parseKML(data, style, loadIcon)
+}
+
+// KMLIconLoader loads the icon image referenced by the href of a KML IconStyle
+type KMLIconLoader func(href string) (image.Image, error)
+
+// ParseKML converts the placemarks of a KML document to map objects: Points become Markers (labeled with short names,
+// see ParseGeoJSON), LineStrings and LinearRings become Paths, and Polygons become Areas (with their inner boundaries as
+// holes); MultiGeometries are converted recursively. Placemarks are styled by their shared (Style, StyleMap) and inline
+// styles, falling back to style (nil selects NewFeatureStyle). Icons are not loaded, see ParseKMLWithIcons.
+func ParseKML(data []byte, style *FeatureStyle) ([]MapObject, error) {
+	return parseKML(data, style, nil)
+}
+
+// ParseKMLWithIcons is like ParseKML, but Points whose style has an icon become ImageMarkers, if loadIcon loads the
+// icon; LoadKMLIcon loads icons from the file system or from http(s) URLs.
+func ParseKMLWithIcons(data []byte, style *FeatureStyle, loadIcon KMLIconLoader) ([]MapObject, error) {
+	return parseKML(data, style, loadIcon)
+}
+
+func parseKML(data []byte, style *FeatureStyle, loadIcon KMLIconLoader) ([]MapObject, error) {
+	var root kmlContainer
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if style == nil {
+		style = NewFeatureStyle()
+	}
+	p := &kmlParser{
+		style:     style,
+		styles:    make(map[string]*kmlStyle),
+		styleMaps: make(map[string]*kmlStyleMap),
+		loadIcon:  loadIcon,
+		icons:     make(map[string]image.Image),
+	}
+	p.collectStyles(&root)
+	objects := make([]MapObject, 0)
+	if err := p.convertContainer(&root, &objects); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func (p *kmlParser) collectStyles(c *kmlContainer) {
+	for i := range c.Styles {
+		p.styles[c.Styles[i].ID] = &c.Styles[i]
+	}
+	for i := range c.StyleMaps {
+		p.styleMaps[c.StyleMaps[i].ID] = &c.StyleMaps[i]
+	}
+	for i := range c.Documents {
+		p.collectStyles(&c.Documents[i])
+	}
+	for i := range c.Folders {
+		p.collectStyles(&c.Folders[i])
+	}
+}
+
+func (p *kmlParser) convertContainer(c *kmlContainer, objects *[]MapObject) error {
+	for i := range c.Placemarks {
+		if err := p.convertPlacemark(&c.Placemarks[i], objects); err != nil {
+			return err
+		}
+	}
+	for i := range c.Documents {
+		if err := p.convertContainer(&c.Documents[i], objects); err != nil {
+			return err
+		}
+	}
+	for i := range c.Folders {
+		if err := p.convertContainer(&c.Folders[i], objects); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kmlPlacemarkStyle is the effective style of a placemark
+type kmlPlacemarkStyle struct {
+	FeatureStyle
+	label   string
+	outline bool
+	icon    image.Image
+	offsetX float64
+	offsetY float64
+}
+
+func (p *kmlParser) convertPlacemark(placemark *kmlPlacemark, objects *[]MapObject) error {
+	s := &kmlPlacemarkStyle{FeatureStyle: *p.style, label: markerLabel(strings.TrimSpace(placemark.Name)), outline: true}
+	if shared := p.resolveStyleURL(placemark.StyleURL, 0); shared != nil {
+		if err := p.applyStyle(s, shared); err != nil {
+			return err
+		}
+	}
+	if placemark.Style != nil {
+		if err := p.applyStyle(s, placemark.Style); err != nil {
+			return err
+		}
+	}
+	return s.convertGeometry(&placemark.kmlGeometry, objects)
+}
+
+// resolveStyleURL returns the style referenced by a local style URL ("#id"), following the "normal" pair of style maps
+func (p *kmlParser) resolveStyleURL(url string, depth int) *kmlStyle {
+	if !strings.HasPrefix(url, "#") || depth > 8 {
+		return nil
+	}
+	id := strings.TrimPrefix(url, "#")
+	if style, ok := p.styles[id]; ok {
+		return style
+	}
+	if styleMap, ok := p.styleMaps[id]; ok {
+		for _, pair := range styleMap.Pairs {
+			if pair.Key == "normal" {
+				return p.resolveStyleURL(pair.StyleURL, depth+1)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *kmlParser) applyStyle(s *kmlPlacemarkStyle, style *kmlStyle) error {
+	if style.LineStyle != nil {
+		if err := applyKMLColor(&s.Stroke, style.LineStyle.Color); err != nil {
+			return err
+		}
+		if style.LineStyle.Width != nil {
+			s.StrokeWidth = *style.LineStyle.Width
+		}
+	}
+	if poly := style.PolyStyle; poly != nil {
+		if err := applyKMLColor(&s.Fill, poly.Color); err != nil {
+			return err
+		}
+		if poly.Fill != nil && *poly.Fill == 0 {
+			s.Fill = color.Transparent
+		}
+		if poly.Outline != nil {
+			s.outline = *poly.Outline != 0
+		}
+	}
+	if icon := style.IconStyle; icon != nil {
+		if err := applyKMLColor(&s.MarkerColor, icon.Color); err != nil {
+			return err
+		}
+		if icon.Href != "" && p.loadIcon != nil {
+			p.applyIcon(s, icon)
+		}
+	}
+	return nil
+}
+
+// applyIcon loads and scales the icon, and determines the offset of its hot spot; icons that cannot be loaded are
+// ignored, such that the placemark becomes a Marker
+func (p *kmlParser) applyIcon(s *kmlPlacemarkStyle, icon *kmlIconStyle) {
+	img, ok := p.icons[icon.Href]
+	if !ok {
+		var err error
+		if img, err = p.loadIcon(icon.Href); err != nil {
+			log.Printf("Failed to load KML icon '%s': %v", icon.Href, err)
+		}
+		p.icons[icon.Href] = img
+	}
+	if img == nil {
+		return
+	}
+
+	if icon.Scale != nil && *icon.Scale > 0 && *icon.Scale != 1 {
+		size := img.Bounds().Size()
+		w := int(float64(size.X)**icon.Scale + 0.5)
+		h := int(float64(size.Y)**icon.Scale + 0.5)
+		if w > 0 && h > 0 {
+			scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+			draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, img.Bounds(), draw.Src, nil)
+			img = scaled
+		}
+	}
+	s.icon = img
+
+	// the hot spot defaults to the icon's center; KML's y axis points upwards
+	size := img.Bounds().Size()
+	s.offsetX, s.offsetY = 0.5*float64(size.X), 0.5*float64(size.Y)
+	if hs := icon.HotSpot; hs != nil {
+		s.offsetX = kmlHotSpotOffset(hs.X, hs.XUnits, float64(size.X))
+		s.offsetY = float64(size.Y) - kmlHotSpotOffset(hs.Y, hs.YUnits, float64(size.Y))
+	}
+}
+
+func kmlHotSpotOffset(value float64, units string, size float64) float64 {
+	switch units {
+	case "pixels":
+		return value
+	case "insetPixels":
+		return size - value
+	}
+	return value * size
+}
+
+func (s *kmlPlacemarkStyle) convertGeometry(g *kmlGeometry, objects *[]MapObject) error {
+	for _, point := range g.Points {
+		positions, err := parseKMLCoordinates(point.Coordinates)
+		if err != nil {
+			return err
+		}
+		for _, pos := range positions {
+			if s.icon != nil {
+				*objects = append(*objects, NewImageMarker(pos, s.icon, s.offsetX, s.offsetY))
+			} else {
+				marker := NewMarker(pos, s.MarkerColor, s.MarkerSize)
+				marker.Label = s.label
+				*objects = append(*objects, marker)
+			}
+		}
+	}
+	for _, lines := range [][]kmlCoordinates{g.LineStrings, g.LinearRings} {
+		for _, line := range lines {
+			positions, err := parseKMLCoordinates(line.Coordinates)
+			if err != nil {
+				return err
+			}
+			*objects = append(*objects, NewPath(positions, s.Stroke, s.StrokeWidth))
+		}
+	}
+	for _, polygon := range g.Polygons {
+		rings := make([][]s2.LatLng, 0, 1+len(polygon.Inner))
+		for _, ring := range append([]kmlCoordinates{polygon.Outer}, polygon.Inner...) {
+			positions, err := parseKMLCoordinates(ring.Coordinates)
+			if err != nil {
+				return err
+			}
+			rings = append(rings, positions)
+		}
+		stroke := s.Stroke
+		if !s.outline {
+			stroke = color.Transparent
+		}
+		*objects = append(*objects, newRingsArea(rings, stroke, s.Fill, s.StrokeWidth))
+	}
+	for i := range g.MultiGeometries {
+		if err := s.convertGeometry(&g.MultiGeometries[i], objects); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseKMLCoordinates parses whitespace separated "lng,lat[,alt]" tuples
+func parseKMLCoordinates(s string) ([]s2.LatLng, error) {
+	positions := make([]s2.LatLng, 0)
+	for _, tuple := range strings.Fields(s) {
+		values := strings.Split(tuple, ",")
+		if len(values) < 2 {
+			return nil, fmt.Errorf("bad KML coordinates: '%s'", tuple)
+		}
+		lng, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad KML coordinates: '%s'", tuple)
+		}
+		lat, err := strconv.ParseFloat(values[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad KML coordinates: '%s'", tuple)
+		}
+		positions = append(positions, s2.LatLngFromDegrees(lat, lng))
+	}
+	return positions, nil
+}
+
+// applyKMLColor parses a KML color ("aabbggrr"); empty strings leave col unchanged
+func applyKMLColor(col *color.Color, s string) error {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if s == "" {
+		return nil
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil || len(s) != 8 {
+		return fmt.Errorf("bad KML color: '%s'", s)
+	}
+	*col = color.NRGBA{uint8(v), uint8(v >> 8), uint8(v >> 16), uint8(v >> 24)}
+	return nil
+}
+
+// readKMZDocument returns the main KML document of a KMZ archive, i.e. "doc.kml" or the first ".kml" file
+func readKMZDocument(archive *zip.Reader) ([]byte, error) {
+	var document *zip.File
+	for _, file := range archive.File {
+		if strings.EqualFold(path.Ext(file.Name), ".kml") && (document == nil || file.Name == "doc.kml") {
+			document = file
+		}
+	}
+	if document == nil {
+		return nil, fmt.Errorf("no KML document in KMZ archive")
+	}
+	return readZipFile(document)
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// loadKMZIcon loads an icon from the KMZ archive, falling back to loadIconFile for icons outside of the archive
+func loadKMZIcon(archive *zip.Reader, href string, loadIconFile KMLIconLoader) (image.Image, error) {
+	name := path.Clean(strings.TrimPrefix(href, "./"))
+	for _, file := range archive.File {
+		if file.Name == name {
+			data, err := readZipFile(file)
+			if err != nil {
+				return nil, err
+			}
+			img, _, err := image.Decode(bytes.NewReader(data))
+			return img, err
+		}
+	}
+	return loadIconFile(href)
+}
+
+// kmlIconClient downloads KML icons; unlike http.DefaultClient, it gives up on unresponsive servers
+var kmlIconClient = &http.Client{Timeout: 30 * time.Second}
+
+func isKMLIconURL(href string) bool {
+	return strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://")
+}
+
+// LoadKMLIcon loads a KML icon from an http(s) URL or a file (relative paths are resolved against the current
+// directory); it can be passed to ParseKMLWithIcons.
+func LoadKMLIcon(href string) (image.Image, error) {
+	var r io.Reader
+	if isKMLIconURL(href) {
+		req, err := http.NewRequest("GET", href, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("User-Agent", defaultUserAgent)
+		resp, err := kmlIconClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GET %s: %s", href, resp.Status)
+		}
+		r = resp.Body
+	} else {
+		file, err := os.Open(href)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	}
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+// loadKMLMarkers loads the Points of a KML file as Markers (ignoring icons) for the 'kml:' marker token
+func loadKMLMarkers(fileName string, col color.Color, size float64) ([]*Marker, error) {
+	style := NewFeatureStyle()
+	style.MarkerColor = col
+	style.MarkerSize = size
+	objects, err := loadKML(fileName, style, false)
+	return markersOf(objects), err
+}
+
+// loadKMLImageMarkers loads the Points with icons of a KML file as ImageMarkers for the 'kml:' image marker token
+func loadKMLImageMarkers(fileName string) ([]*ImageMarker, error) {
+	objects, err := LoadKML(fileName, nil)
+	if err != nil {
+		return nil, err
+	}
+	markers := make([]*ImageMarker, 0)
+	for _, object := range objects {
+		if m, ok := object.(*ImageMarker); ok {
+			markers = append(markers, m)
+		}
+	}
+	return markers, nil
+}
+
+// loadKMLPaths loads the LineStrings and LinearRings of a KML file as Paths for the 'kml:' path token
+func loadKMLPaths(fileName string, col color.Color, weight float64) ([]*Path, error) {
+	style := NewFeatureStyle()
+	style.Stroke = col
+	style.StrokeWidth = weight
+	objects, err := loadKML(fileName, style, false)
+	return pathsOf(objects), err
+}
+
+// loadKMLAreas loads the Polygons of a KML file as Areas for the 'kml:' area token
+func loadKMLAreas(fileName string, col color.Color, fill color.Color, weight float64) ([]*Area, error) {
+	style := NewFeatureStyle()
+	style.Stroke = col
+	style.Fill = fill
+	style.StrokeWidth = weight
+	objects, err := loadKML(fileName, style, false)
+	return areasOf(objects), err
+}
@@ -0,0 +1,134 @@
+package sm
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+const testKML = `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Document>
+    <Style id="blue-line"><LineStyle><color>ffff0000</color><width>4</width></LineStyle></Style>
+    <Style id="pin"><IconStyle><color>ff00ff00</color><scale>2</scale><Icon><href>icon.png</href></Icon><hotSpot x="0.5" y="0" xunits="fraction" yunits="fraction"/></IconStyle></Style>
+    <StyleMap id="line-map"><Pair><key>normal</key><styleUrl>#blue-line</styleUrl></Pair><Pair><key>highlight</key><styleUrl>#pin</styleUrl></Pair></StyleMap>
+    <Folder>
+      <Placemark>
+        <name>A</name>
+        <styleUrl>#pin</styleUrl>
+        <Point><coordinates>13.4,52.5,0</coordinates></Point>
+      </Placemark>
+      <Placemark>
+        <name>Route</name>
+        <styleUrl>#line-map</styleUrl>
+        <MultiGeometry>
+          <LineString><coordinates>13.4,52.5 13.5,52.6</coordinates></LineString>
+          <Polygon>
+            <outerBoundaryIs><LinearRing><coordinates>13,52 14,52 14,53 13,52</coordinates></LinearRing></outerBoundaryIs>
+            <innerBoundaryIs><LinearRing><coordinates>13.4,52.2 13.6,52.2 13.6,52.4 13.4,52.2</coordinates></LinearRing></innerBoundaryIs>
+          </Polygon>
+        </MultiGeometry>
+        <Style><PolyStyle><color>800000ff</color><outline>0</outline></PolyStyle></Style>
+      </Placemark>
+    </Folder>
+  </Document>
+</kml>`
+
+func TestParseKML(t *testing.T) {
+	objects, err := ParseKML([]byte(testKML), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("unexpected number of objects: %d", len(objects))
+	}
+
+	marker, ok := objects[0].(*Marker)
+	if !ok || marker.Position != s2.LatLngFromDegrees(52.5, 13.4) || marker.Label != "A" || marker.Color != (color.NRGBA{0, 0xff, 0, 0xff}) {
+		t.Errorf("unexpected marker: %v", objects[0])
+	}
+	path, ok := objects[1].(*Path)
+	if !ok || len(path.Positions) != 2 || path.Color != (color.NRGBA{0, 0, 0xff, 0xff}) || path.Weight != 4 {
+		t.Errorf("unexpected path: %v", objects[1])
+	}
+	area, ok := objects[2].(*Area)
+	if !ok || len(area.Positions) != 3 || area.Fill != (color.NRGBA{0xff, 0, 0, 0x80}) || area.Color != color.Transparent {
+		t.Errorf("unexpected area: %v", objects[2])
+	}
+
+	if _, err := ParseKML([]byte(`<kml><Placemark><Point><coordinates>13.4</coordinates></Point></Placemark></kml>`), nil); err == nil {
+		t.Error("error expected for bad coordinates")
+	}
+	if _, err := ParseKML([]byte(`<kml><Placemark><Style><LineStyle><color>red</color></LineStyle></Style></Placemark></kml>`), nil); err == nil {
+		t.Error("error expected for bad color")
+	}
+}
+
+func TestParseKMLWithIcons(t *testing.T) {
+	hrefs := make([]string, 0)
+	loadIcon := func(href string) (image.Image, error) {
+		hrefs = append(hrefs, href)
+		return image.NewRGBA(image.Rect(0, 0, 8, 10)), nil
+	}
+	objects, err := ParseKMLWithIcons([]byte(testKML), nil, loadIcon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hrefs) != 1 || hrefs[0] != "icon.png" {
+		t.Errorf("unexpected icon loads: %v", hrefs)
+	}
+	if marker, ok := objects[0].(*ImageMarker); !ok || marker.Img.Bounds().Size() != image.Pt(16, 20) {
+		t.Errorf("unexpected image marker: %v", objects[0])
+	}
+}
+
+func TestLoadKMZ(t *testing.T) {
+	var icon bytes.Buffer
+	if err := png.Encode(&icon, image.NewRGBA(image.Rect(0, 0, 8, 10))); err != nil {
+		t.Fatal(err)
+	}
+	var kmz bytes.Buffer
+	archive := zip.NewWriter(&kmz)
+	for name, data := range map[string][]byte{"doc.kml": []byte(testKML), "icon.png": icon.Bytes()} {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fileName := filepath.Join(t.TempDir(), "test.kmz")
+	if err := os.WriteFile(fileName, kmz.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	markers, err := ParseImageMarkerString("kml:" + fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(markers) != 1 {
+		t.Fatalf("unexpected number of image markers: %d", len(markers))
+	}
+	if size := markers[0].Img.Bounds().Size(); size != image.Pt(16, 20) || markers[0].OffsetX != 8 || markers[0].OffsetY != 20 {
+		t.Errorf("unexpected image marker: %v %v %v", size, markers[0].OffsetX, markers[0].OffsetY)
+	}
+
+	paths, err := ParsePathString("weight:2|kml:" + fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || paths[0].Weight != 4 {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+}
@@ -22,6 +22,14 @@ type MapObject interface {
 	Draw(dc *gg.Context, trans *Transformer)
 }
 
+// CanvasObject is implemented by map objects that can be drawn to any Canvas (e.g. for vector output), not only to a gg.Context.
+type CanvasObject interface {
+	MapObject
+
+	// DrawCanvas draws the object to the given canvas.
+	DrawCanvas(c Canvas, trans *Transformer)
+}
+
 // CanDisplay checks if pos is generally displayable (i.e. its latitude is in [-85,85])
 func CanDisplay(pos s2.LatLng) bool {
 	const minLatitude float64 = -85.0
@@ -120,6 +120,11 @@ func ParseMarkerString(s string) ([]*Marker, error) {
 			if err != nil {
 				return nil, err
 			}
+		} else if isFile, fileMarkers, err := parseMarkerFileToken(ss, markerColor, size); isFile {
+			if err != nil {
+				return nil, err
+			}
+			markers = append(markers, fileMarkers...)
 		} else {
 			lat, lng, err := coordsparser.Parse(ss)
 			if err != nil {
@@ -138,6 +143,27 @@ func ParseMarkerString(s string) ([]*Marker, error) {
 	return markers, nil
 }
 
+// parseMarkerFileToken loads the markers of a 'gpx:', 'kml:', 'wkt:', or 'shp:' token
+func parseMarkerFileToken(token string, col color.Color, size float64) (bool, []*Marker, error) {
+	if ok, suffix := hasPrefix(token, "gpx:"); ok {
+		markers, err := loadGPXMarkers(suffix, col, size)
+		return true, markers, err
+	}
+	if ok, suffix := hasPrefix(token, "kml:"); ok {
+		markers, err := loadKMLMarkers(suffix, col, size)
+		return true, markers, err
+	}
+	if ok, suffix := hasPrefix(token, "wkt:"); ok {
+		markers, err := loadWKTMarkers(suffix, col, size)
+		return true, markers, err
+	}
+	if ok, suffix := hasPrefix(token, "shp:"); ok {
+		markers, err := loadShapefileMarkers(suffix, col, size)
+		return true, markers, err
+	}
+	return false, nil, nil
+}
+
 // SetLabelColor sets the color of the marker's text label
 func (m *Marker) SetLabelColor(col color.Color) {
 	m.LabelColor = col
@@ -157,6 +183,11 @@ func (m *Marker) Bounds() s2.Rect {
 
 // Draw draws the object in the given graphical context.
 func (m *Marker) Draw(gc *gg.Context, trans *Transformer) {
+	m.DrawCanvas(gc, trans)
+}
+
+// DrawCanvas draws the object to the given canvas.
+func (m *Marker) DrawCanvas(gc Canvas, trans *Transformer) {
 	if !CanDisplay(m.Position) {
 		log.Printf("Marker coordinates not displayable: %f/%f", m.Position.Lat.Degrees(), m.Position.Lng.Degrees())
 		return
@@ -173,7 +204,7 @@ func (m *Marker) Draw(gc *gg.Context, trans *Transformer) {
 	gc.ClosePath()
 	gc.SetColor(m.Color)
 	gc.FillPreserve()
-	gc.SetRGB(0, 0, 0)
+	gc.SetColor(color.RGBA{0x00, 0x00, 0x00, 0xff})
 	gc.Stroke()
 
 	if m.Label != "" {
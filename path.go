@@ -13,7 +13,6 @@ import (
 	"github.com/flopp/go-coordsparser"
 	"github.com/fogleman/gg"
 	"github.com/golang/geo/s2"
-	"github.com/tkrajina/gpxgo/gpx"
 )
 
 // Path represents a path or area on the map
@@ -40,9 +39,17 @@ func ParsePathString(s string) ([]*Path, error) {
 	currentPath := new(Path)
 	currentPath.Color = color.RGBA{0xff, 0, 0, 0xff}
 	currentPath.Weight = 5.0
+	tracks := make([]string, 0)
 
-	for _, ss := range strings.Split(s, "|") {
-		if ok, suffix := hasPrefix(ss, "color:"); ok {
+	tokens := strings.Split(s, "|")
+	for i, ss := range tokens {
+		if isEnc, positions, err := parseEncodedPolylineToken(tokens[i:]); isEnc {
+			if err != nil {
+				return nil, err
+			}
+			currentPath.Positions = append(currentPath.Positions, positions...)
+			break
+		} else if ok, suffix := hasPrefix(ss, "color:"); ok {
 			var err error
 			if currentPath.Color, err = ParseColorString(suffix); err != nil {
 				return nil, err
@@ -52,24 +59,13 @@ func ParsePathString(s string) ([]*Path, error) {
 			if currentPath.Weight, err = strconv.ParseFloat(suffix, 64); err != nil {
 				return nil, err
 			}
-		} else if ok, suffix := hasPrefix(ss, "gpx:"); ok {
-			gpxData, err := gpx.ParseFile(suffix)
+		} else if ok, suffix := hasPrefix(ss, "track:"); ok {
+			tracks = append(tracks, suffix)
+		} else if isFile, filePaths, err := parsePathFileToken(ss, tracks, currentPath.Color, currentPath.Weight); isFile {
 			if err != nil {
 				return nil, err
 			}
-			for _, trk := range gpxData.Tracks {
-				for _, seg := range trk.Segments {
-					p := new(Path)
-					p.Color = currentPath.Color
-					p.Weight = currentPath.Weight
-					for _, pt := range seg.Points {
-						p.Positions = append(p.Positions, s2.LatLngFromDegrees(pt.GetLatitude(), pt.GetLongitude()))
-					}
-					if len(p.Positions) > 0 {
-						paths = append(paths, p)
-					}
-				}
-			}
+			paths = append(paths, filePaths...)
 		} else {
 			lat, lng, err := coordsparser.Parse(ss)
 			if err != nil {
@@ -84,6 +80,31 @@ func ParsePathString(s string) ([]*Path, error) {
 	return paths, nil
 }
 
+// parsePathFileToken loads the paths of a 'gpx:' (restricted to the selected tracks), 'geojson:', 'kml:', 'wkt:', or 'shp:' token
+func parsePathFileToken(token string, tracks []string, col color.Color, weight float64) (bool, []*Path, error) {
+	if ok, suffix := hasPrefix(token, "gpx:"); ok {
+		paths, err := loadGPXPaths(suffix, tracks, col, weight)
+		return true, paths, err
+	}
+	if ok, suffix := hasPrefix(token, "geojson:"); ok {
+		paths, err := loadGeoJSONPaths(suffix, col, weight)
+		return true, paths, err
+	}
+	if ok, suffix := hasPrefix(token, "kml:"); ok {
+		paths, err := loadKMLPaths(suffix, col, weight)
+		return true, paths, err
+	}
+	if ok, suffix := hasPrefix(token, "wkt:"); ok {
+		paths, err := loadWKTPaths(suffix, col, weight)
+		return true, paths, err
+	}
+	if ok, suffix := hasPrefix(token, "shp:"); ok {
+		paths, err := loadShapefilePaths(suffix, col, weight)
+		return true, paths, err
+	}
+	return false, nil, nil
+}
+
 // ExtraMarginPixels returns the left, top, right, bottom pixel margin of the Path object, which is exactly the line width.
 func (p *Path) ExtraMarginPixels() (float64, float64, float64, float64) {
 	return p.Weight, p.Weight, p.Weight, p.Weight
@@ -100,6 +121,11 @@ func (p *Path) Bounds() s2.Rect {
 
 // Draw draws the object in the given graphical context.
 func (p *Path) Draw(gc *gg.Context, trans *Transformer) {
+	p.DrawCanvas(gc, trans)
+}
+
+// DrawCanvas draws the object to the given canvas.
+func (p *Path) DrawCanvas(gc Canvas, trans *Transformer) {
 	if len(p.Positions) <= 1 {
 		return
 	}
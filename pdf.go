@@ -0,0 +1,460 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// PDFOptions specifies the physical page size and resolution of PDF output
+type PDFOptions struct {
+	// PageWidth and PageHeight are the page dimensions in millimeters; if zero, the page size is derived from the Context's pixel size and DPI
+	PageWidth  float64
+	PageHeight float64
+	// DPI is the resolution of the map tiles, i.e. the number of map pixels per inch; defaults to 72
+	DPI float64
+}
+
+// PaperSize returns the width and height in millimeters of the paper format with the given name (e.g. "A4", "letter");
+// the suffix "-landscape" swaps width and height.
+func PaperSize(name string) (float64, float64, error) {
+	sizes := map[string][2]float64{
+		"a0":     {841, 1189},
+		"a1":     {594, 841},
+		"a2":     {420, 594},
+		"a3":     {297, 420},
+		"a4":     {210, 297},
+		"a5":     {148, 210},
+		"a6":     {105, 148},
+		"letter": {215.9, 279.4},
+		"legal":  {215.9, 355.6},
+	}
+	n := strings.ToLower(name)
+	landscape := false
+	if strings.HasSuffix(n, "-landscape") {
+		n = strings.TrimSuffix(n, "-landscape")
+		landscape = true
+	}
+	size, ok := sizes[n]
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown paper size: '%s'", name)
+	}
+	if landscape {
+		return size[1], size[0], nil
+	}
+	return size[0], size[1], nil
+}
+
+// RenderPDF renders the map as single page PDF document to w.
+//
+// The map's pixel size is determined by the page size and the DPI (e.g. 2480x3508 pixels for A4 at 300 DPI), i.e. the size
+// set with SetSize is ignored, unless no page size is given.
+// The map tiles (and the background) are embedded as a single image; map objects implementing CanvasObject
+// (e.g. markers, paths, areas, circles) and the attribution are written as vector graphics and text. Other map objects are
+// rasterized and embedded as images.
+func (m *Context) RenderPDF(w io.Writer, opts PDFOptions) error {
+	dpi := opts.DPI
+	if dpi <= 0 {
+		dpi = 72
+	}
+
+	// work on a shallow copy, such that the page size does not leak into m
+	c := *m
+	if opts.PageWidth > 0 && opts.PageHeight > 0 {
+		c.SetSize(int(math.Round(opts.PageWidth/25.4*dpi)), int(math.Round(opts.PageHeight/25.4*dpi)))
+	}
+	if c.width <= 0 || c.height <= 0 {
+		return fmt.Errorf("invalid map size: %dx%d", c.width, c.height)
+	}
+
+	zoom, trans, err := c.croppedTransformer()
+	if err != nil {
+		return err
+	}
+
+	doc := newPDFDocument()
+	canvas := newPDFCanvas(doc)
+	if err := c.drawToCanvas(canvas, zoom, trans); err != nil {
+		return err
+	}
+
+	scale := 72.0 / dpi
+	return doc.write(w, float64(c.width)*scale, float64(c.height)*scale, scale, canvas.content.Bytes())
+}
+
+// pdfDocument collects the objects of a single page PDF document
+type pdfDocument struct {
+	objects [][]byte
+	images  []int
+	states  map[uint8]int
+}
+
+func newPDFDocument() *pdfDocument {
+	d := new(pdfDocument)
+	d.states = make(map[uint8]int)
+	return d
+}
+
+// add adds an object and returns its object number
+func (d *pdfDocument) add(object []byte) int {
+	d.objects = append(d.objects, object)
+	return len(d.objects)
+}
+
+// addStream adds a flate-compressed stream object and returns its object number
+func (d *pdfDocument) addStream(dict string, data []byte) int {
+	var buf bytes.Buffer
+	z := zlib.NewWriter(&buf)
+	z.Write(data)
+	z.Close()
+
+	var object bytes.Buffer
+	fmt.Fprintf(&object, "<< %s/Filter /FlateDecode /Length %d >>\nstream\n", dict, buf.Len())
+	object.Write(buf.Bytes())
+	object.WriteString("\nendstream")
+	return d.add(object.Bytes())
+}
+
+// addImage adds the image (and its alpha channel as soft mask) and returns the image's resource index
+func (d *pdfDocument) addImage(img image.Image) int {
+	b := img.Bounds()
+	rgb := make([]byte, 0, 3*b.Dx()*b.Dy())
+	alpha := make([]byte, 0, b.Dx()*b.Dy())
+	opaque := true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			opaque = opaque && c.A == 0xff
+		}
+	}
+
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /BitsPerComponent 8", b.Dx(), b.Dy())
+	if !opaque {
+		mask := d.addStream(dict+" /ColorSpace /DeviceGray ", alpha)
+		dict += fmt.Sprintf(" /SMask %d 0 R", mask)
+	}
+	d.images = append(d.images, d.addStream(dict+" /ColorSpace /DeviceRGB ", rgb))
+	return len(d.images) - 1
+}
+
+// state returns the name of a graphics state with the given opacity
+func (d *pdfDocument) state(alpha uint8) string {
+	if _, ok := d.states[alpha]; !ok {
+		a := float64(alpha) / 255.0
+		d.states[alpha] = d.add([]byte(fmt.Sprintf("<< /Type /ExtGState /ca %s /CA %s >>", pdfNumber(a), pdfNumber(a))))
+	}
+	return fmt.Sprintf("GS%d", alpha)
+}
+
+// write writes the complete document with a single page of the given size (in points); the content stream uses pixel
+// coordinates, which are scaled by 'scale'.
+func (d *pdfDocument) write(w io.Writer, width, height, scale float64, content []byte) error {
+	var header bytes.Buffer
+	fmt.Fprintf(&header, "%s 0 0 %s 0 %s cm\n", pdfNumber(scale), pdfNumber(-scale), pdfNumber(height))
+	contentObj := d.addStream("", append(header.Bytes(), content...))
+
+	var resources strings.Builder
+	resources.WriteString("<< /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >> >>")
+	resources.WriteString(" /XObject <<")
+	for i, obj := range d.images {
+		fmt.Fprintf(&resources, " /Im%d %d 0 R", i, obj)
+	}
+	resources.WriteString(" >> /ExtGState <<")
+	// iterate over the alpha values rather than the map, such that the output is deterministic
+	for alpha := 0; alpha <= 0xff; alpha++ {
+		if obj, ok := d.states[uint8(alpha)]; ok {
+			fmt.Fprintf(&resources, " /GS%d %d 0 R", alpha, obj)
+		}
+	}
+	resources.WriteString(" >> >>")
+
+	pagesObj := len(d.objects) + 2
+	pageObj := d.add([]byte(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+		pagesObj, pdfNumber(width), pdfNumber(height), resources.String(), contentObj)))
+	d.add([]byte(fmt.Sprintf("<< /Type /Pages /Kids [%d 0 R] /Count 1 >>", pageObj)))
+	catalogObj := d.add([]byte(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj)))
+
+	out := bufio.NewWriter(w)
+	counter := &countingWriter{w: out}
+	fmt.Fprintf(counter, "%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int64, len(d.objects))
+	for i, object := range d.objects {
+		offsets[i] = counter.n
+		fmt.Fprintf(counter, "%d 0 obj\n", i+1)
+		counter.Write(object)
+		fmt.Fprintf(counter, "\nendobj\n")
+	}
+	xref := counter.n
+	fmt.Fprintf(counter, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(counter, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(counter, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.objects)+1, catalogObj, xref)
+	return out.Flush()
+}
+
+// countingWriter counts the bytes written to the underlying writer
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// pdfCanvas is a Canvas that writes PDF content stream operators; coordinates are in pixels with the origin at the top left corner
+type pdfCanvas struct {
+	doc       *pdfDocument
+	content   bytes.Buffer
+	path      strings.Builder
+	hasPath   bool
+	current   bool
+	color     color.Color
+	lineWidth float64
+	lineCap   gg.LineCap
+	lineJoin  gg.LineJoin
+	fillRule  gg.FillRule
+	face      font.Face
+}
+
+func newPDFCanvas(doc *pdfDocument) *pdfCanvas {
+	c := new(pdfCanvas)
+	c.doc = doc
+	c.color = color.Black
+	c.lineWidth = 1.0
+	c.lineCap = gg.LineCapRound
+	c.lineJoin = gg.LineJoinRound
+	c.face = basicfont.Face7x13
+	return c
+}
+
+// ClearPath clears the current path.
+func (c *pdfCanvas) ClearPath() {
+	c.path.Reset()
+	c.hasPath = false
+	c.current = false
+}
+
+// MoveTo starts a new subpath at the given point.
+func (c *pdfCanvas) MoveTo(x, y float64) {
+	fmt.Fprintf(&c.path, "%s %s m\n", pdfNumber(x), pdfNumber(y))
+	c.hasPath = true
+	c.current = true
+}
+
+// LineTo adds a line segment to the current path; it starts a new subpath if there is no current point.
+func (c *pdfCanvas) LineTo(x, y float64) {
+	if !c.current {
+		c.MoveTo(x, y)
+		return
+	}
+	fmt.Fprintf(&c.path, "%s %s l\n", pdfNumber(x), pdfNumber(y))
+}
+
+// ClosePath closes the current subpath.
+func (c *pdfCanvas) ClosePath() {
+	if c.hasPath {
+		c.path.WriteString("h\n")
+		c.current = false
+	}
+}
+
+// DrawArc adds a circular arc from angle1 to angle2 (radians, clockwise) to the current path.
+func (c *pdfCanvas) DrawArc(x, y, r, angle1, angle2 float64) {
+	c.LineTo(x+r*math.Cos(angle1), y+r*math.Sin(angle1))
+	// approximate by cubic bezier curves spanning at most 90° each
+	n := int(math.Ceil(math.Abs(angle2-angle1) / (0.5 * math.Pi)))
+	for i := 0; i < n; i++ {
+		a1 := angle1 + (angle2-angle1)*float64(i)/float64(n)
+		a2 := angle1 + (angle2-angle1)*float64(i+1)/float64(n)
+		k := 4.0 / 3.0 * math.Tan((a2-a1)/4)
+		fmt.Fprintf(&c.path, "%s %s %s %s %s %s c\n",
+			pdfNumber(x+r*(math.Cos(a1)-k*math.Sin(a1))), pdfNumber(y+r*(math.Sin(a1)+k*math.Cos(a1))),
+			pdfNumber(x+r*(math.Cos(a2)+k*math.Sin(a2))), pdfNumber(y+r*(math.Sin(a2)-k*math.Cos(a2))),
+			pdfNumber(x+r*math.Cos(a2)), pdfNumber(y+r*math.Sin(a2)))
+	}
+}
+
+// DrawRectangle adds a closed rectangle subpath to the current path.
+func (c *pdfCanvas) DrawRectangle(x, y, w, h float64) {
+	c.MoveTo(x, y)
+	c.LineTo(x+w, y)
+	c.LineTo(x+w, y+h)
+	c.LineTo(x, y+h)
+	c.ClosePath()
+}
+
+// SetLineWidth sets the line width for stroking.
+func (c *pdfCanvas) SetLineWidth(lineWidth float64) {
+	c.lineWidth = lineWidth
+}
+
+// SetLineCap sets the line cap style for stroking.
+func (c *pdfCanvas) SetLineCap(lineCap gg.LineCap) {
+	c.lineCap = lineCap
+}
+
+// SetLineJoin sets the line join style for stroking.
+func (c *pdfCanvas) SetLineJoin(lineJoin gg.LineJoin) {
+	c.lineJoin = lineJoin
+}
+
+// SetColor sets the color for filling, stroking, and text.
+func (c *pdfCanvas) SetColor(col color.Color) {
+	c.color = col
+}
+
+// SetFillRule sets the fill rule, which determines the inside of self-intersecting paths and paths with multiple subpaths.
+func (c *pdfCanvas) SetFillRule(fillRule gg.FillRule) {
+	c.fillRule = fillRule
+}
+
+// SetFontFace sets the font face that is used for measuring text; the PDF text is rendered with the Courier font scaled to the same size.
+func (c *pdfCanvas) SetFontFace(fontFace font.Face) {
+	c.face = fontFace
+}
+
+// Fill fills the current path and clears it afterwards.
+func (c *pdfCanvas) Fill() {
+	c.FillPreserve()
+	c.ClearPath()
+}
+
+// FillPreserve fills the current path without clearing it.
+func (c *pdfCanvas) FillPreserve() {
+	if !c.hasPath || isTransparent(c.color) {
+		return
+	}
+	operator := "f"
+	if c.fillRule == gg.FillRuleEvenOdd {
+		operator = "f*"
+	}
+	fmt.Fprintf(&c.content, "q\n%s%s%s%s\nQ\n", c.alpha(), pdfColor(c.color, "rg"), c.path.String(), operator)
+}
+
+// Stroke strokes the current path and clears it afterwards.
+func (c *pdfCanvas) Stroke() {
+	if c.hasPath && !isTransparent(c.color) && c.lineWidth > 0 {
+		fmt.Fprintf(&c.content, "q\n%s%s%s w %d J %d j\n%sS\nQ\n", c.alpha(), pdfColor(c.color, "RG"),
+			pdfNumber(c.lineWidth), pdfLineCap(c.lineCap), pdfLineJoin(c.lineJoin), c.path.String())
+	}
+	c.ClearPath()
+}
+
+// DrawImage draws the image with its top left corner at the given point.
+func (c *pdfCanvas) DrawImage(im image.Image, x, y int) {
+	size := im.Bounds().Size()
+	index := c.doc.addImage(im)
+	fmt.Fprintf(&c.content, "q\n%d 0 0 %d %d %d cm\n/Im%d Do\nQ\n", size.X, -size.Y, x, y+size.Y, index)
+}
+
+// DrawStringAnchored draws the text at the given point; ax, ay specify the relative anchor position within the text's bounding box.
+func (c *pdfCanvas) DrawStringAnchored(s string, x, y, ax, ay float64) {
+	text := pdfText(s)
+	if isTransparent(c.color) || len(text) == 0 {
+		return
+	}
+	w, h := c.MeasureString(s)
+	x -= ax * w
+	y += ay * h
+	// the line height of typical fonts is ~1.2 times their size; Courier's glyphs are 0.6 times the font size wide, and
+	// pdfText encodes each rune as a single glyph (with escapes taking multiple bytes)
+	size := h / 1.2
+	scaling := 100.0 * w / (0.6 * size * float64(utf8.RuneCountInString(s)))
+	fmt.Fprintf(&c.content, "q\n%s%sBT\n/F1 %s Tf\n%s Tz\n1 0 0 -1 %s %s Tm\n(%s) Tj\nET\nQ\n", c.alpha(), pdfColor(c.color, "rg"),
+		pdfNumber(size), pdfNumber(scaling), pdfNumber(x), pdfNumber(y), text)
+}
+
+// MeasureString returns the width and height of the rendered text.
+func (c *pdfCanvas) MeasureString(s string) (float64, float64) {
+	d := &font.Drawer{Face: c.face}
+	return float64(d.MeasureString(s)) / 64, float64(c.face.Metrics().Height) / 64
+}
+
+// alpha returns an operator setting the opacity of the current color, or an empty string if the color is opaque
+func (c *pdfCanvas) alpha() string {
+	a := color.NRGBAModel.Convert(c.color).(color.NRGBA).A
+	if a == 0xff {
+		return ""
+	}
+	return fmt.Sprintf("/%s gs\n", c.doc.state(a))
+}
+
+// pdfNumber formats a number with up to three decimals
+func pdfNumber(v float64) string {
+	s := fmt.Sprintf("%.3f", v)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// pdfColor returns the operator setting the fill ("rg") or stroke ("RG") color
+func pdfColor(col color.Color, operator string) string {
+	c := color.NRGBAModel.Convert(col).(color.NRGBA)
+	return fmt.Sprintf("%s %s %s %s\n", pdfNumber(float64(c.R)/255.0), pdfNumber(float64(c.G)/255.0), pdfNumber(float64(c.B)/255.0), operator)
+}
+
+func pdfLineCap(lineCap gg.LineCap) int {
+	switch lineCap {
+	case gg.LineCapButt:
+		return 0
+	case gg.LineCapRound:
+		return 1
+	case gg.LineCapSquare:
+		return 2
+	}
+	return 1
+}
+
+func pdfLineJoin(lineJoin gg.LineJoin) int {
+	switch lineJoin {
+	case gg.LineJoinRound:
+		return 1
+	case gg.LineJoinBevel:
+		return 2
+	}
+	return 1
+}
+
+// pdfText converts s to an escaped string literal in WinAnsi encoding; unsupported characters are replaced by '?'
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
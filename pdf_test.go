@@ -0,0 +1,101 @@
+package sm
+
+import (
+	"bytes"
+	"image/color"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestRenderPDF(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetSize(100, 100)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.OverrideAttribution("attribution (c)")
+
+	coords1 := s2.LatLngFromDegrees(48.123, 7.0)
+	coords2 := s2.LatLngFromDegrees(48.987, 8.0)
+	ctx.AddObject(NewPath([]s2.LatLng{coords1, coords2}, color.RGBA{0, 0, 255, 255}, 4.0))
+	ctx.AddObject(NewCircle(coords1, color.RGBA{255, 0, 0, 128}, color.RGBA{0, 255, 0, 128}, 1000.0, 2.0))
+
+	w, h, err := PaperSize("A4-landscape")
+	if err != nil || w != 297 || h != 210 {
+		t.Fatalf("unexpected paper size: %v %v %v", w, h, err)
+	}
+
+	var buf bytes.Buffer
+	if err := ctx.RenderPDF(&buf, PDFOptions{PageWidth: w, PageHeight: h, DPI: 150}); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	data := buf.Bytes()
+	if !bytes.HasPrefix(data, []byte("%PDF-")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("invalid PDF header or trailer")
+	}
+	if !bytes.Contains(data, []byte("/MediaBox [0 0 841.92 595.2]")) {
+		t.Errorf("unexpected page size")
+	}
+
+	// check that the xref offsets point to the objects
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if m == nil {
+		t.Fatalf("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	if len(entries) == 0 {
+		t.Fatalf("empty xref table")
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if !bytes.HasPrefix(data[offset:], []byte(strconv.Itoa(i+1)+" 0 obj")) {
+			t.Errorf("bad xref offset for object %d", i+1)
+		}
+	}
+
+	if ctx.width != 100 || ctx.height != 100 {
+		t.Errorf("RenderPDF modified the context size")
+	}
+}
+
+func TestPDFCanvasTextScaling(t *testing.T) {
+	scaling := func(s string) string {
+		c := newPDFCanvas(nil)
+		c.DrawStringAnchored(s, 0, 0, 0, 0)
+		m := regexp.MustCompile(`([\d.]+) Tz`).FindSubmatch(c.content.Bytes())
+		if m == nil {
+			t.Fatalf("missing text scaling: %s", c.content.String())
+		}
+		return string(m[1])
+	}
+	// escaped characters must not affect the scaling of the monospaced glyphs
+	if a, b := scaling("abc"), scaling("(©)"); a != b {
+		t.Errorf("unexpected text scaling: %s != %s", a, b)
+	}
+}
+
+func TestRenderPDFDeterministic(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetSize(100, 100)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.OverrideAttribution("attribution (c)")
+	coords := s2.LatLngFromDegrees(48.123, 7.0)
+	for _, alpha := range []uint8{0x10, 0x40, 0x80, 0xc0, 0xf0} {
+		ctx.AddObject(NewCircle(coords, color.NRGBA{0xff, 0, 0, alpha}, color.NRGBA{0, 0xff, 0, alpha}, 1000.0, 2.0))
+	}
+
+	var first []byte
+	for i := 0; i < 10; i++ {
+		var buf bytes.Buffer
+		if err := ctx.RenderPDF(&buf, PDFOptions{PageWidth: 100, PageHeight: 100, DPI: 72}); err != nil {
+			t.Fatalf("failed to render: %v", err)
+		}
+		if first == nil {
+			first = buf.Bytes()
+		} else if !bytes.Equal(first, buf.Bytes()) {
+			t.Fatal("expected identical PDF documents")
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/golang/geo/s2"
+)
+
+// DecodePolyline decodes a polyline encoded with Google's polyline algorithm; precision is the number of decimal places
+// of the encoded coordinates, i.e. 5 (Google, OSRM) or 6 (Valhalla, OSRM's "polyline6").
+func DecodePolyline(s string, precision int) ([]s2.LatLng, error) {
+	factor := math.Pow10(precision)
+	positions := make([]s2.LatLng, 0)
+	lat, lng := 0, 0
+	for i := 0; i < len(s); {
+		dLat, n, err := decodePolylineValue(s[i:])
+		if err != nil {
+			return nil, err
+		}
+		i += n
+		dLng, n, err := decodePolylineValue(s[i:])
+		if err != nil {
+			return nil, err
+		}
+		i += n
+		lat += dLat
+		lng += dLng
+		positions = append(positions, s2.LatLngFromDegrees(float64(lat)/factor, float64(lng)/factor))
+	}
+	return positions, nil
+}
+
+// decodePolylineValue decodes a single value, and returns it along with the number of consumed bytes
+func decodePolylineValue(s string) (int, int, error) {
+	result, shift := 0, 0
+	for i := 0; i < len(s); i++ {
+		c := int(s[i]) - 63
+		if c < 0 || c > 63 || shift > 30 {
+			return 0, 0, fmt.Errorf("bad encoded polyline character: '%c'", s[i])
+		}
+		result |= (c & 0x1f) << shift
+		shift += 5
+		if c < 0x20 {
+			if result&1 != 0 {
+				return ^(result >> 1), i + 1, nil
+			}
+			return result >> 1, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("truncated encoded polyline")
+}
+
+// EncodePolyline encodes the positions with Google's polyline algorithm using the given precision (see DecodePolyline).
+func EncodePolyline(positions []s2.LatLng, precision int) string {
+	factor := math.Pow10(precision)
+	var b strings.Builder
+	prevLat, prevLng := 0, 0
+	for _, pos := range positions {
+		lat := int(math.Round(pos.Lat.Degrees() * factor))
+		lng := int(math.Round(pos.Lng.Degrees() * factor))
+		encodePolylineValue(&b, lat-prevLat)
+		encodePolylineValue(&b, lng-prevLng)
+		prevLat, prevLng = lat, lng
+	}
+	return b.String()
+}
+
+func encodePolylineValue(b *strings.Builder, value int) {
+	v := value << 1
+	if value < 0 {
+		v = ^v
+	}
+	for v >= 0x20 {
+		b.WriteByte(byte((0x20 | (v & 0x1f)) + 63))
+		v >>= 5
+	}
+	b.WriteByte(byte(v + 63))
+}
+
+// parseEncodedPolylineToken decodes the polyline of an 'enc:' (precision 5) or 'enc6:' (precision 6) token; as encoded
+// polylines may contain '|', the token extends to the end of the string, i.e. it consumes all remaining tokens.
+func parseEncodedPolylineToken(tokens []string) (bool, []s2.LatLng, error) {
+	precision := 5
+	ok, suffix := hasPrefix(tokens[0], "enc:")
+	if !ok {
+		if ok, suffix = hasPrefix(tokens[0], "enc6:"); !ok {
+			return false, nil, nil
+		}
+		precision = 6
+	}
+	positions, err := DecodePolyline(strings.Join(append([]string{suffix}, tokens[1:]...), "|"), precision)
+	return true, positions, err
+}
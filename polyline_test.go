@@ -0,0 +1,73 @@
+package sm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+// the example of Google's polyline algorithm documentation
+const testPolyline = "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+
+var testPolylinePositions = []s2.LatLng{
+	s2.LatLngFromDegrees(38.5, -120.2),
+	s2.LatLngFromDegrees(40.7, -120.95),
+	s2.LatLngFromDegrees(43.252, -126.453),
+}
+
+func TestDecodePolyline(t *testing.T) {
+	positions, err := DecodePolyline(testPolyline, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != len(testPolylinePositions) {
+		t.Fatalf("unexpected number of positions: %d", len(positions))
+	}
+	for i, pos := range positions {
+		expected := testPolylinePositions[i]
+		if math.Abs(pos.Lat.Degrees()-expected.Lat.Degrees()) > 1e-9 || math.Abs(pos.Lng.Degrees()-expected.Lng.Degrees()) > 1e-9 {
+			t.Errorf("unexpected position %d: %v", i, pos)
+		}
+	}
+
+	for _, bad := range []string{"_p~iF", "_p~iF~ps|", "_p~iF ps|U"} {
+		if _, err := DecodePolyline(bad, 5); err == nil {
+			t.Errorf("error expected for '%s'", bad)
+		}
+	}
+}
+
+func TestEncodePolyline(t *testing.T) {
+	if s := EncodePolyline(testPolylinePositions, 5); s != testPolyline {
+		t.Errorf("unexpected encoded polyline: %s", s)
+	}
+
+	positions, err := DecodePolyline(EncodePolyline(testPolylinePositions, 6), 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, pos := range positions {
+		if math.Abs(pos.Lng.Degrees()-testPolylinePositions[i].Lng.Degrees()) > 1e-9 {
+			t.Errorf("unexpected position %d: %v", i, pos)
+		}
+	}
+}
+
+func TestParsePathStringEncoded(t *testing.T) {
+	paths, err := ParsePathString("color:blue|weight:2|enc:" + testPolyline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || len(paths[0].Positions) != 3 || paths[0].Weight != 2 {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+
+	area, err := ParseAreaString("fill:red|enc6:" + EncodePolyline(testPolylinePositions, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(area.Positions) != 3 {
+		t.Errorf("unexpected area: %v", area)
+	}
+}
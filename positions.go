@@ -0,0 +1,311 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// ObjectPosition describes where a map object ended up in the rendered image
+type ObjectPosition struct {
+	// Index is the index of the object in the order of addition to the Context
+	Index  int
+	Object MapObject
+	// BBox is the pixel bounding box of the object, clipped to the image
+	BBox image.Rectangle
+	// Polygon is the outline of the object's filled shape (e.g. of markers, areas, and circles) in pixel coordinates; it is empty for objects without filled shape (e.g. paths)
+	Polygon []image.Point
+}
+
+// RenderResult is the rendered map image along with the pixel positions of all visible map objects
+type RenderResult struct {
+	Image       image.Image
+	Transformer *Transformer
+	Objects     []ObjectPosition
+}
+
+// RenderWithPositions renders the map image as specified by opts, and determines the pixel positions of all map objects
+// that are (at least partially) visible.
+//
+// The positions of objects implementing CanvasObject are derived from the actually drawn shapes; for other objects, they are
+// derived from the geographical bounds and the extra margin pixels.
+func (m *Context) RenderWithPositions(opts RenderOptions) (*RenderResult, error) {
+	img, trans, err := m.RenderWithOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RenderResult{Image: img, Transformer: trans}
+	for i, object := range m.objects {
+		position := objectPosition(object, trans)
+		position.Index = i
+		position.BBox = position.BBox.Intersect(img.Bounds())
+		if position.BBox.Empty() {
+			continue
+		}
+		result.Objects = append(result.Objects, position)
+	}
+	return result, nil
+}
+
+func objectPosition(object MapObject, trans *Transformer) ObjectPosition {
+	if o, ok := object.(CanvasObject); ok {
+		c := newShapeCanvas()
+		o.DrawCanvas(c, trans)
+		return ObjectPosition{Object: object, BBox: c.extent, Polygon: c.polygon}
+	}
+
+	return ObjectPosition{Object: object, BBox: objectPixelBounds(object, trans)}
+}
+
+// objectPixelBounds returns the pixel bounding box of the object's geographical bounds, enlarged by its extra margin pixels
+func objectPixelBounds(object MapObject, trans *Transformer) image.Rectangle {
+	bounds := object.Bounds()
+	x0, y0 := trans.LatLngToXY(bounds.Vertex(3))
+	x1, y1 := trans.LatLngToXY(bounds.Vertex(1))
+	if x1 < x0 {
+		// the bounds cross the antimeridian
+		x1 += trans.numTiles * float64(trans.tileSize)
+	}
+	left, top, right, bottom := object.ExtraMarginPixels()
+	return image.Rect(int(math.Floor(x0-left)), int(math.Floor(y0-top)), int(math.Ceil(x1+right)), int(math.Ceil(y1+bottom)))
+}
+
+// objectType returns a short name of the object's type
+func objectType(object MapObject) string {
+	switch object.(type) {
+	case *Marker:
+		return "marker"
+	case *ImageMarker:
+		return "imagemarker"
+	case *Path:
+		return "path"
+	case *Area:
+		return "area"
+	case *Circle:
+		return "circle"
+	case *Ellipse:
+		return "ellipse"
+	case *Sector:
+		return "sector"
+	}
+	return "object"
+}
+
+// HTMLImageMap returns an HTML <map> element with the given name and an <area> element for each visible map object; the
+// topmost object comes first, such that it takes precedence. The optional function attributes returns additional attributes
+// (e.g. "href", "title") of the area element of an object; objects without attributes are omitted.
+func (r *RenderResult) HTMLImageMap(name string, attributes func(position ObjectPosition) map[string]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<map name=\"%s\">\n", html.EscapeString(name))
+	for i := len(r.Objects) - 1; i >= 0; i-- {
+		position := r.Objects[i]
+		var attrs map[string]string
+		if attributes != nil {
+			if attrs = attributes(position); len(attrs) == 0 {
+				continue
+			}
+		}
+
+		if len(position.Polygon) >= 3 {
+			coords := make([]string, 0, 2*len(position.Polygon))
+			for _, p := range position.Polygon {
+				coords = append(coords, fmt.Sprintf("%d,%d", p.X, p.Y))
+			}
+			fmt.Fprintf(&b, `  <area shape="poly" coords="%s"`, strings.Join(coords, ","))
+		} else {
+			bb := position.BBox
+			fmt.Fprintf(&b, `  <area shape="rect" coords="%d,%d,%d,%d"`, bb.Min.X, bb.Min.Y, bb.Max.X, bb.Max.Y)
+		}
+		keys := make([]string, 0, len(attrs))
+		for key := range attrs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, ` %s="%s"`, html.EscapeString(key), html.EscapeString(attrs[key]))
+		}
+		b.WriteString(">\n")
+	}
+	b.WriteString("</map>\n")
+	return b.String()
+}
+
+type jsonObjectPosition struct {
+	Index   int      `json:"index"`
+	Type    string   `json:"type"`
+	BBox    [4]int   `json:"bbox"`
+	Polygon [][2]int `json:"polygon,omitempty"`
+}
+
+type jsonRenderResult struct {
+	Width   int                  `json:"width"`
+	Height  int                  `json:"height"`
+	Objects []jsonObjectPosition `json:"objects"`
+}
+
+// WriteJSON writes the image size and the object positions as JSON document to w; bounding boxes are given as [minX, minY, maxX, maxY].
+func (r *RenderResult) WriteJSON(w io.Writer) error {
+	size := r.Image.Bounds().Size()
+	doc := jsonRenderResult{Width: size.X, Height: size.Y, Objects: make([]jsonObjectPosition, 0, len(r.Objects))}
+	for _, position := range r.Objects {
+		bb := position.BBox
+		o := jsonObjectPosition{Index: position.Index, Type: objectType(position.Object), BBox: [4]int{bb.Min.X, bb.Min.Y, bb.Max.X, bb.Max.Y}}
+		for _, p := range position.Polygon {
+			o.Polygon = append(o.Polygon, [2]int{p.X, p.Y})
+		}
+		doc.Objects = append(doc.Objects, o)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// shapeCanvas is a Canvas that records the extent of the drawn shapes instead of drawing them
+type shapeCanvas struct {
+	path      [][]image.Point
+	polygon   []image.Point
+	extent    image.Rectangle
+	lineWidth float64
+	face      font.Face
+}
+
+func newShapeCanvas() *shapeCanvas {
+	c := new(shapeCanvas)
+	c.lineWidth = 1.0
+	c.face = basicfont.Face7x13
+	return c
+}
+
+func (c *shapeCanvas) add(r image.Rectangle) {
+	if c.extent.Empty() {
+		c.extent = r
+	} else {
+		c.extent = c.extent.Union(r)
+	}
+}
+
+// ClearPath clears the current path.
+func (c *shapeCanvas) ClearPath() {
+	c.path = nil
+}
+
+// MoveTo starts a new subpath at the given point.
+func (c *shapeCanvas) MoveTo(x, y float64) {
+	c.path = append(c.path, []image.Point{image.Pt(int(math.Round(x)), int(math.Round(y)))})
+}
+
+// LineTo adds a point to the current subpath; it starts a new subpath if there is none.
+func (c *shapeCanvas) LineTo(x, y float64) {
+	if len(c.path) == 0 {
+		c.MoveTo(x, y)
+		return
+	}
+	last := len(c.path) - 1
+	c.path[last] = append(c.path[last], image.Pt(int(math.Round(x)), int(math.Round(y))))
+}
+
+// ClosePath does nothing, as subpaths are recorded as polygons anyway.
+func (c *shapeCanvas) ClosePath() {}
+
+// DrawArc adds points along the circular arc from angle1 to angle2 (radians) to the current subpath.
+func (c *shapeCanvas) DrawArc(x, y, r, angle1, angle2 float64) {
+	const n = 16
+	for i := 0; i <= n; i++ {
+		a := angle1 + (angle2-angle1)*float64(i)/n
+		c.LineTo(x+r*math.Cos(a), y+r*math.Sin(a))
+	}
+}
+
+// DrawRectangle adds a rectangle subpath to the current path.
+func (c *shapeCanvas) DrawRectangle(x, y, w, h float64) {
+	c.MoveTo(x, y)
+	c.LineTo(x+w, y)
+	c.LineTo(x+w, y+h)
+	c.LineTo(x, y+h)
+}
+
+// SetLineWidth sets the line width, which enlarges the extent of stroked paths.
+func (c *shapeCanvas) SetLineWidth(lineWidth float64) {
+	c.lineWidth = lineWidth
+}
+
+// SetLineCap does nothing.
+func (c *shapeCanvas) SetLineCap(lineCap gg.LineCap) {}
+
+// SetLineJoin does nothing.
+func (c *shapeCanvas) SetLineJoin(lineJoin gg.LineJoin) {}
+
+// SetColor does nothing.
+func (c *shapeCanvas) SetColor(col color.Color) {}
+
+// SetFillRule does nothing.
+func (c *shapeCanvas) SetFillRule(fillRule gg.FillRule) {}
+
+// SetFontFace sets the font face that is used for measuring text.
+func (c *shapeCanvas) SetFontFace(fontFace font.Face) {
+	c.face = fontFace
+}
+
+// Fill records the extent of the current path and clears it afterwards.
+func (c *shapeCanvas) Fill() {
+	c.FillPreserve()
+	c.ClearPath()
+}
+
+// FillPreserve records the extent of the current path; the first filled subpath becomes the polygon.
+func (c *shapeCanvas) FillPreserve() {
+	c.addPath(0)
+	if c.polygon == nil && len(c.path) > 0 {
+		c.polygon = append([]image.Point{}, c.path[0]...)
+	}
+}
+
+// Stroke records the extent of the current path, enlarged by half the line width, and clears it afterwards.
+func (c *shapeCanvas) Stroke() {
+	c.addPath(int(math.Ceil(0.5 * c.lineWidth)))
+	c.ClearPath()
+}
+
+// addPath adds the extent of the current path, enlarged by margin pixels
+func (c *shapeCanvas) addPath(margin int) {
+	for _, subpath := range c.path {
+		for _, p := range subpath {
+			c.add(image.Rect(p.X-margin, p.Y-margin, p.X+margin+1, p.Y+margin+1))
+		}
+	}
+}
+
+// DrawImage records the extent of the image with its top left corner at the given point.
+func (c *shapeCanvas) DrawImage(im image.Image, x, y int) {
+	c.add(im.Bounds().Sub(im.Bounds().Min).Add(image.Pt(x, y)))
+}
+
+// DrawStringAnchored records the extent of the text.
+func (c *shapeCanvas) DrawStringAnchored(s string, x, y, ax, ay float64) {
+	w, h := c.MeasureString(s)
+	x -= ax * w
+	y += ay * h
+	c.add(image.Rect(int(math.Floor(x)), int(math.Floor(y-h)), int(math.Ceil(x+w)), int(math.Ceil(y))))
+}
+
+// MeasureString returns the width and height of the rendered text.
+func (c *shapeCanvas) MeasureString(s string) (float64, float64) {
+	d := &font.Drawer{Face: c.face}
+	return float64(d.MeasureString(s)) / 64, float64(c.face.Metrics().Height) / 64
+}
@@ -0,0 +1,71 @@
+package sm
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func renderTestPositions(t *testing.T) *RenderResult {
+	ctx := NewContext()
+	ctx.SetSize(400, 300)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.SetCenter(s2.LatLngFromDegrees(48.0, 7.0))
+	ctx.SetZoom(10)
+
+	ctx.AddObject(NewPath([]s2.LatLng{s2.LatLngFromDegrees(48.0, 6.9), s2.LatLngFromDegrees(48.0, 7.1)}, color.Black, 4.0))
+	ctx.AddObject(NewMarker(s2.LatLngFromDegrees(48.0, 7.0), color.RGBA{255, 0, 0, 255}, 16.0))
+	ctx.AddObject(NewMarker(s2.LatLngFromDegrees(10.0, 7.0), color.RGBA{255, 0, 0, 255}, 16.0))
+
+	result, err := ctx.RenderWithPositions(RenderOptions{})
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if len(result.Objects) != 2 {
+		t.Fatalf("expected 2 visible objects, got %d", len(result.Objects))
+	}
+	return result
+}
+
+func TestRenderWithPositions(t *testing.T) {
+	result := renderTestPositions(t)
+
+	// the marker's tip is at the image center, its head above
+	marker := result.Objects[1]
+	if marker.Index != 1 || len(marker.Polygon) < 3 {
+		t.Fatalf("unexpected marker position: %+v", marker)
+	}
+	if bb := marker.BBox; bb.Min.X > 192 || bb.Max.X < 208 || bb.Max.Y < 150 || bb.Max.Y > 152 || bb.Min.Y > 126 {
+		t.Errorf("unexpected marker bounding box: %v", bb)
+	}
+}
+
+func TestRenderResultOutputs(t *testing.T) {
+	result := renderTestPositions(t)
+
+	imageMap := result.HTMLImageMap("objects", func(position ObjectPosition) map[string]string {
+		if position.Index == 1 {
+			return map[string]string{"href": "#marker", "title": "<marker>"}
+		}
+		return nil
+	})
+	if strings.Count(imageMap, "<area ") != 1 || !strings.Contains(imageMap, `shape="poly"`) || !strings.Contains(imageMap, `title="&lt;marker&gt;"`) {
+		t.Errorf("unexpected image map: %s", imageMap)
+	}
+
+	var buf bytes.Buffer
+	if err := result.WriteJSON(&buf); err != nil {
+		t.Fatalf("failed to write JSON: %v", err)
+	}
+	var doc jsonRenderResult
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.Width != 400 || len(doc.Objects) != 2 || doc.Objects[0].Type != "path" || doc.Objects[1].Type != "marker" {
+		t.Errorf("unexpected JSON: %s", buf.String())
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/geo/s2"
+)
+
+// TileWriter stores rendered (and encoded) tiles
+type TileWriter interface {
+	// WriteTile stores the tile with the given XYZ coordinates
+	WriteTile(zoom, x, y int, data []byte) error
+}
+
+// DirectoryTileWriter stores tiles as files in a 'zoom/x/y.ext' directory structure
+type DirectoryTileWriter struct {
+	root      string
+	extension string
+	perm      os.FileMode
+}
+
+// NewDirectoryTileWriter creates a DirectoryTileWriter, which stores tiles below the root directory using the given file extension (e.g. "png").
+func NewDirectoryTileWriter(root string, extension string, perm os.FileMode) *DirectoryTileWriter {
+	return &DirectoryTileWriter{root: root, extension: extension, perm: perm}
+}
+
+// WriteTile stores the tile as file 'root/zoom/x/y.ext'.
+func (w *DirectoryTileWriter) WriteTile(zoom, x, y int, data []byte) error {
+	dir := filepath.Join(w.root, fmt.Sprintf("%d", zoom), fmt.Sprintf("%d", x))
+	if err := os.MkdirAll(dir, w.perm); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.%s", y, w.extension)), data, 0644)
+}
+
+// MBTilesWriter stores tiles in an MBTiles (SQLite) database
+type MBTilesWriter struct {
+	db *sql.DB
+}
+
+// NewMBTilesWriter creates the MBTiles tables in the database (if necessary) and stores the metadata (e.g. "name", "format",
+// "bounds", "minzoom", "maxzoom"). The database has to be opened by the caller using an SQLite driver of their choice.
+func NewMBTilesWriter(db *sql.DB, metadata map[string]string) (*MBTilesWriter, error) {
+	statements := []string{
+		"CREATE TABLE IF NOT EXISTS metadata (name TEXT, value TEXT)",
+		"CREATE UNIQUE INDEX IF NOT EXISTS metadata_index ON metadata (name)",
+		"CREATE TABLE IF NOT EXISTS tiles (zoom_level INTEGER, tile_column INTEGER, tile_row INTEGER, tile_data BLOB)",
+		"CREATE UNIQUE INDEX IF NOT EXISTS tile_index ON tiles (zoom_level, tile_column, tile_row)",
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range metadata {
+		if _, err := db.Exec("INSERT OR REPLACE INTO metadata (name, value) VALUES (?, ?)", name, value); err != nil {
+			return nil, err
+		}
+	}
+	return &MBTilesWriter{db: db}, nil
+}
+
+// WriteTile stores the tile; as demanded by the MBTiles specification, the y coordinate is flipped (TMS scheme).
+func (w *MBTilesWriter) WriteTile(zoom, x, y int, data []byte) error {
+	row := (1 << uint(zoom)) - 1 - y
+	_, err := w.db.Exec("INSERT OR REPLACE INTO tiles (zoom_level, tile_column, tile_row, tile_data) VALUES (?, ?, ?, ?)", zoom, x, row, data)
+	return err
+}
+
+// TilePyramidOptions specifies the tiles rendered by RenderTilePyramid
+type TilePyramidOptions struct {
+	// MinZoom and MaxZoom specify the zoom range
+	MinZoom int
+	MaxZoom int
+	// BBox is the geographical area covered by the tiles
+	BBox s2.Rect
+	// TileSize is the tile size in pixels; defaults to 256
+	TileSize int
+	// Encoding specifies the image encoding of the tiles
+	Encoding EncodeOptions
+}
+
+// RenderTilePyramid renders the map objects (but no map tiles, background, or attribution) to transparent XYZ tiles
+// covering the bounding box for all zoom levels of the zoom range, and stores them using w. Each tile is rendered with the
+// objects, whose bounds (enlarged by their extra margin pixels) intersect the tile; tiles without drawn pixels (e.g. next
+// to a diagonal path) are skipped.
+func (m *Context) RenderTilePyramid(opts TilePyramidOptions, w TileWriter) error {
+	tileSize := opts.TileSize
+	if tileSize <= 0 {
+		tileSize = 256
+	}
+	if opts.MinZoom < 0 || opts.MaxZoom < opts.MinZoom || opts.MaxZoom > 30 {
+		return fmt.Errorf("invalid zoom range: %d-%d", opts.MinZoom, opts.MaxZoom)
+	}
+	if opts.BBox.IsEmpty() {
+		return fmt.Errorf("empty bounding box")
+	}
+
+	for zoom := opts.MinZoom; zoom <= opts.MaxZoom; zoom++ {
+		minX, minY, maxX, maxY := tileRange(opts.BBox, zoom)
+		numTiles := 1 << uint(zoom)
+		for xx := minX; xx <= maxX; xx++ {
+			x := ((xx % numTiles) + numTiles) % numTiles
+			for y := minY; y <= maxY; y++ {
+				if err := m.renderPyramidTile(zoom, x, y, tileSize, opts.Encoding, w); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Context) renderPyramidTile(zoom, x, y, tileSize int, encoding EncodeOptions, w TileWriter) error {
+	trans := tileTransformer(zoom, x, y, tileSize)
+	bounds := image.Rect(0, 0, tileSize, tileSize)
+
+	var img *image.RGBA
+	var gc *gg.Context
+	for _, object := range m.objects {
+		if object.Bounds().IsEmpty() || !objectPixelBounds(object, trans).Overlaps(bounds) {
+			continue
+		}
+		if img == nil {
+			img = image.NewRGBA(bounds)
+			gc = gg.NewContextForRGBA(img)
+		}
+		object.Draw(gc, trans)
+	}
+	if img == nil || isEmptyImage(img) {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeImage(&buf, img, encoding); err != nil {
+		return err
+	}
+	return w.WriteTile(zoom, x, y, buf.Bytes())
+}
+
+// tileRange returns the XYZ tile coordinates covering the bounding box; for bounding boxes crossing the antimeridian, maxX exceeds the number of tiles
+func tileRange(bbox s2.Rect, zoom int) (int, int, int, int) {
+	t := &Transformer{numTiles: math.Exp2(float64(zoom)), proj: s2.NewMercatorProjection(0.5)}
+	x0, y0 := t.ll2t(bbox.Vertex(3))
+	x1, y1 := t.ll2t(bbox.Vertex(1))
+	if x1 < x0 {
+		x1 += t.numTiles
+	}
+	clamp := func(v float64) int {
+		return int(math.Max(0, math.Min(t.numTiles-1, math.Floor(v))))
+	}
+	return int(math.Floor(x0)), clamp(y0), int(math.Min(math.Floor(x1), math.Floor(x0)+t.numTiles-1)), clamp(y1)
+}
+
+// tileTransformer returns a Transformer for the XYZ tile; objects are wrapped around the antimeridian such that they are
+// placed within half the world width of the tile center, so that objects extending beyond the tile edges are drawn properly.
+func tileTransformer(zoom, x, y, tileSize int) *Transformer {
+	t := new(Transformer)
+	t.zoom = zoom
+	t.numTiles = math.Exp2(float64(zoom))
+	t.tileSize = tileSize
+	t.proj = s2.NewMercatorProjection(0.5)
+	t.tOriginX, t.tOriginY = x, y
+	t.tCountX, t.tCountY = 1, 1
+	t.pWidth, t.pHeight = tileSize, tileSize
+	t.pCenterX, t.pCenterY = tileSize/2, tileSize/2
+	t.tCenterX = float64(x) + float64(t.pCenterX)/float64(tileSize)
+	t.tCenterY = float64(y) + float64(t.pCenterY)/float64(tileSize)
+	worldWidth := int(t.numTiles) * tileSize
+	t.pMinX = t.pCenterX - worldWidth/2
+	t.pMaxX = t.pMinX + worldWidth
+	return t
+}
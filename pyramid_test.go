@@ -0,0 +1,189 @@
+package sm
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+type memoryTileWriter map[[3]int][]byte
+
+func (w memoryTileWriter) WriteTile(zoom, x, y int, data []byte) error {
+	w[[3]int{zoom, x, y}] = data
+	return nil
+}
+
+func TestRenderTilePyramid(t *testing.T) {
+	ctx := NewContext()
+	p1 := s2.LatLngFromDegrees(48.0, 7.0)
+	p2 := s2.LatLngFromDegrees(48.5, 7.5)
+	ctx.AddObject(NewPath([]s2.LatLng{p1, p2}, color.RGBA{0, 0, 255, 255}, 4.0))
+
+	w := make(memoryTileWriter)
+	bbox := s2.RectFromLatLng(p1).AddPoint(p2)
+	if err := ctx.RenderTilePyramid(TilePyramidOptions{MinZoom: 8, MaxZoom: 10, BBox: bbox}, w); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	// at zoom 8 the path spans the tiles 132/88 and 133/88
+	if _, ok := w[[3]int{8, 133, 88}]; !ok || len(w) < 3 {
+		t.Fatalf("unexpected tiles: %d", len(w))
+	}
+	for key, data := range w {
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("tile %v: failed to decode: %v", key, err)
+		}
+		if img.Bounds().Dx() != 256 || img.Bounds().Dy() != 256 {
+			t.Errorf("tile %v: unexpected size %v", key, img.Bounds())
+		}
+		if key[0] > 8 && img.At(0, 0).(color.NRGBA).A != 0 {
+			t.Errorf("tile %v: expected transparent corner", key)
+		}
+	}
+}
+
+func TestTileTransformer(t *testing.T) {
+	trans := tileTransformer(3, 5, 2, 256)
+	x, y := trans.LatLngToXY(trans.XYToLatLng(0, 0))
+	if math.Abs(x) > 1e-6 || math.Abs(y) > 1e-6 {
+		t.Errorf("unexpected tile origin: %f %f", x, y)
+	}
+	mx, _ := trans.XYToWebMercator(0, 0)
+	if expected := (5.0/8.0 - 0.5) * 2 * math.Pi * webMercatorRadius; math.Abs(mx-expected) > 1e-6 {
+		t.Errorf("unexpected tile origin: %f, expected %f", mx, expected)
+	}
+	// points to the west of the tile must not be wrapped to the east
+	if x, _ := trans.LatLngToXY(trans.XYToLatLng(-100, 0)); math.Abs(x+100) > 1e-6 {
+		t.Errorf("unexpected wrapping: %f", x)
+	}
+}
+
+func TestRenderTilePyramidSkipsEmptyTiles(t *testing.T) {
+	ctx := NewContext()
+	p1 := s2.LatLngFromDegrees(48.0, 7.0)
+	p2 := s2.LatLngFromDegrees(48.5, 7.5)
+	ctx.AddObject(NewPath([]s2.LatLng{p1, p2}, color.RGBA{0, 0, 255, 255}, 2.0))
+
+	w := make(memoryTileWriter)
+	bbox := s2.RectFromLatLng(p1).AddPoint(p2)
+	if err := ctx.RenderTilePyramid(TilePyramidOptions{MinZoom: 12, MaxZoom: 12, BBox: bbox}, w); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	// the diagonal path crosses only a fraction of the tiles overlapping its bounding box
+	minX, minY, maxX, maxY := tileRange(bbox, 12)
+	if len(w) == 0 || len(w) >= (maxX-minX+1)*(maxY-minY+1)/2 {
+		t.Errorf("unexpected number of tiles: %d", len(w))
+	}
+	for key, data := range w {
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("tile %v: failed to decode: %v", key, err)
+		}
+		rgba := image.NewRGBA(img.Bounds())
+		draw.Draw(rgba, rgba.Bounds(), img, image.Point{}, draw.Src)
+		if isEmptyImage(rgba) {
+			t.Errorf("tile %v: empty tile", key)
+		}
+	}
+}
+
+func TestDirectoryTileWriter(t *testing.T) {
+	root := t.TempDir()
+	w := NewDirectoryTileWriter(root, "png", 0755)
+	if err := w.WriteTile(3, 5, 2, []byte("tile")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "3", "5", "2.png"))
+	if err != nil || string(data) != "tile" {
+		t.Errorf("unexpected tile file: %q, %v", data, err)
+	}
+}
+
+// recordingDriver is a database/sql driver, which records the executed statements and their arguments
+type recordingDriver struct {
+	statements []string
+	args       [][]driver.Value
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{d}, nil
+}
+
+type recordingConn struct {
+	d *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{c.d, query}, nil
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type recordingStmt struct {
+	d     *recordingDriver
+	query string
+}
+
+func (s *recordingStmt) Close() error {
+	return nil
+}
+
+func (s *recordingStmt) NumInput() int {
+	return -1
+}
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.statements = append(s.d.statements, s.query)
+	s.d.args = append(s.d.args, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("queries are not supported")
+}
+
+func TestMBTilesWriter(t *testing.T) {
+	d := &recordingDriver{}
+	sql.Register("recording-mbtiles", d)
+	db, err := sql.Open("recording-mbtiles", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	w, err := NewMBTilesWriter(db, map[string]string{"format": "png"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.statements) != 5 || !strings.HasPrefix(d.statements[4], "INSERT OR REPLACE INTO metadata") || d.args[4][0] != "format" {
+		t.Fatalf("unexpected statements: %v", d.statements)
+	}
+
+	if err := w.WriteTile(3, 5, 2, []byte("tile")); err != nil {
+		t.Fatal(err)
+	}
+	// TMS rows are counted from the bottom, i.e. row 8-1-2 = 5
+	args := d.args[len(d.args)-1]
+	if len(args) != 4 || args[0] != int64(3) || args[1] != int64(5) || args[2] != int64(5) || string(args[3].([]byte)) != "tile" {
+		t.Errorf("unexpected tile arguments: %v", args)
+	}
+}
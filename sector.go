@@ -0,0 +1,93 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/geo/s2"
+)
+
+// Sector represents a geodesic circular sector ("pie slice") on the map
+type Sector struct {
+	MapObject
+	Position     s2.LatLng
+	Color        color.Color
+	Fill         color.Color
+	Weight       float64
+	Radius       float64 // in m.
+	StartBearing float64 // in degrees, clockwise from north
+	EndBearing   float64 // in degrees, clockwise from north
+}
+
+// NewSector creates a new sector spanning clockwise from startBearing to endBearing
+func NewSector(pos s2.LatLng, col, fill color.Color, radius, startBearing, endBearing, weight float64) *Sector {
+	return &Sector{
+		Position:     pos,
+		Color:        col,
+		Fill:         fill,
+		Weight:       weight,
+		Radius:       radius,
+		StartBearing: startBearing,
+		EndBearing:   endBearing,
+	}
+}
+
+// Ring returns the densified polygon of the sector, i.e. the center followed by the points of the arc.
+func (m *Sector) Ring() []s2.LatLng {
+	from := m.StartBearing * math.Pi / 180.0
+	to := m.EndBearing * math.Pi / 180.0
+	for to <= from {
+		to += 2 * math.Pi
+	}
+	for to-from > 2*math.Pi {
+		to -= 2 * math.Pi
+	}
+	arc := geodesicRing(m.Position, from, to, func(float64) float64 { return m.Radius })
+	return append([]s2.LatLng{m.Position}, arc...)
+}
+
+// ExtraMarginPixels returns the left, top, right, bottom pixel margin of the Sector object, which is exactly the line width.
+func (m *Sector) ExtraMarginPixels() (float64, float64, float64, float64) {
+	return m.Weight, m.Weight, m.Weight, m.Weight
+}
+
+// Bounds returns the geographical boundary rect (excluding the actual pixel dimensions).
+func (m *Sector) Bounds() s2.Rect {
+	if m.Radius <= 0 {
+		return s2.RectFromLatLng(m.Position)
+	}
+	return ringBounds(m.Ring())
+}
+
+// Draw draws the object in the given graphical context.
+func (m *Sector) Draw(gc *gg.Context, trans *Transformer) {
+	m.DrawCanvas(gc, trans)
+}
+
+// DrawCanvas draws the object to the given canvas.
+func (m *Sector) DrawCanvas(gc Canvas, trans *Transformer) {
+	if !CanDisplay(m.Position) {
+		log.Printf("Sector coordinates not displayable: %f/%f", m.Position.Lat.Degrees(), m.Position.Lng.Degrees())
+		return
+	}
+	if m.Radius <= 0 {
+		return
+	}
+
+	gc.ClearPath()
+	gc.SetLineWidth(m.Weight)
+	gc.SetLineCap(gg.LineCapRound)
+	gc.SetLineJoin(gg.LineJoinRound)
+	drawRing(gc, trans, m.Position, m.Ring())
+	gc.SetColor(m.Fill)
+	gc.FillPreserve()
+	gc.SetColor(m.Color)
+	gc.Stroke()
+}
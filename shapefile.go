@@ -0,0 +1,381 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/geo/s2"
+)
+
+// Shapefile contains the records of an ESRI shapefile along with the names of the attribute fields
+type Shapefile struct {
+	Fields  []string
+	Records []ShapefileRecord
+}
+
+// ShapefileRecord is a record of a shapefile, i.e. a geometry and its attributes (from the .dbf file)
+type ShapefileRecord struct {
+	Attributes map[string]string
+	geometry   simpleGeometry
+}
+
+// LoadShapefile loads a shapefile, given either as .shp file (with the accompanying .shx, .dbf, and .prj files in the
+// same directory) or as .zip archive containing these files. Points, MultiPoints, PolyLines, and Polygons (including
+// their Z and M variants) are supported. Coordinates are reprojected according to the .prj file, which must specify
+// WGS84 or Web Mercator; without .prj file, WGS84 is assumed. Without .dbf file, the records have no attributes.
+func LoadShapefile(fileName string) (*Shapefile, error) {
+	if strings.EqualFold(filepath.Ext(fileName), ".zip") {
+		archive, err := zip.OpenReader(fileName)
+		if err != nil {
+			return nil, err
+		}
+		defer archive.Close()
+		return readZippedShapefile(&archive.Reader)
+	}
+
+	base := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	return readShapefile(func(ext string) ([]byte, error) {
+		data, err := os.ReadFile(base + ext)
+		if errors.Is(err, fs.ErrNotExist) {
+			// the extensions may be upper case, e.g. "ROADS.SHP"
+			data, err = os.ReadFile(base + strings.ToUpper(ext))
+		}
+		return data, err
+	})
+}
+
+func readZippedShapefile(archive *zip.Reader) (*Shapefile, error) {
+	base := ""
+	for _, file := range archive.File {
+		if strings.EqualFold(path.Ext(file.Name), ".shp") {
+			base = strings.TrimSuffix(file.Name, path.Ext(file.Name))
+			break
+		}
+	}
+	if base == "" {
+		return nil, errors.New("no .shp file in zip archive")
+	}
+	return readShapefile(func(ext string) ([]byte, error) {
+		for _, file := range archive.File {
+			if strings.EqualFold(file.Name, base+ext) {
+				return readZipFile(file)
+			}
+		}
+		return nil, fs.ErrNotExist
+	})
+}
+
+// readShapefile reads a shapefile, whose parts are returned by read given the file extension
+func readShapefile(read func(ext string) ([]byte, error)) (*Shapefile, error) {
+	proj := wgs84ToLatLng
+	if prj, err := read(".prj"); err == nil {
+		if proj, err = shapefileProjection(string(prj)); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	shp, err := read(".shp")
+	if err != nil {
+		return nil, err
+	}
+	shx, err := read(".shx")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	geometries, err := readShapefileGeometries(shp, shx, proj)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Shapefile{Records: make([]ShapefileRecord, len(geometries))}
+	for i := range geometries {
+		s.Records[i].geometry = geometries[i]
+	}
+	dbf, err := read(".dbf")
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, s.readAttributes(dbf)
+}
+
+// shapefileProjection determines the coordinate conversion from the WKT CRS definition of a .prj file
+func shapefileProjection(prj string) (func(x, y float64) s2.LatLng, error) {
+	prj = strings.TrimSpace(prj)
+	upper := strings.ToUpper(prj)
+	switch {
+	case prj == "":
+		return wgs84ToLatLng, nil
+	case strings.HasPrefix(upper, "GEOGCS") && (strings.Contains(upper, "WGS_1984") || strings.Contains(upper, "WGS 84")):
+		return wgs84ToLatLng, nil
+	case strings.HasPrefix(upper, "PROJCS") && (strings.Contains(upper, "MERCATOR_AUXILIARY_SPHERE") ||
+		strings.Contains(upper, "PSEUDO-MERCATOR") || strings.Contains(upper, "POPULAR VISUALISATION") ||
+		strings.Contains(upper, `AUTHORITY["EPSG","3857"]`)):
+		return webMercatorToLatLng, nil
+	}
+	name := prj
+	if i := strings.Index(prj, ","); i >= 0 {
+		name = prj[:i]
+	}
+	return nil, fmt.Errorf("unsupported shapefile CRS: %s", name)
+}
+
+// readShapefileGeometries reads the geometries of the .shp file; the record offsets are taken from the .shx file (if
+// available), otherwise the records are read sequentially
+func readShapefileGeometries(shp []byte, shx []byte, proj func(x, y float64) s2.LatLng) ([]simpleGeometry, error) {
+	if len(shp) < 100 || binary.BigEndian.Uint32(shp) != 9994 {
+		return nil, errors.New("bad .shp file header")
+	}
+	offsets := make([]int, 0)
+	if len(shx) >= 100 {
+		for i := 100; i+8 <= len(shx); i += 8 {
+			offsets = append(offsets, 2*int(binary.BigEndian.Uint32(shx[i:])))
+		}
+	} else {
+		for offset := 100; offset+8 <= len(shp); offset += 8 + 2*int(binary.BigEndian.Uint32(shp[offset+4:])) {
+			offsets = append(offsets, offset)
+		}
+	}
+
+	geometries := make([]simpleGeometry, 0, len(offsets))
+	for i, offset := range offsets {
+		if offset+8 > len(shp) {
+			return nil, fmt.Errorf("bad .shp record %d: offset exceeds file", i+1)
+		}
+		length := 2 * int(binary.BigEndian.Uint32(shp[offset+4:]))
+		if offset+8+length > len(shp) {
+			return nil, fmt.Errorf("bad .shp record %d: length exceeds file", i+1)
+		}
+		g, err := readShapefileRecord(shp[offset+8:offset+8+length], proj)
+		if err != nil {
+			return nil, fmt.Errorf("bad .shp record %d: %v", i+1, err)
+		}
+		geometries = append(geometries, g)
+	}
+	return geometries, nil
+}
+
+// readShapefileRecord reads the geometry of a record; Z and M values are ignored
+func readShapefileRecord(data []byte, proj func(x, y float64) s2.LatLng) (simpleGeometry, error) {
+	var g simpleGeometry
+	r := bytes.NewReader(data)
+	var shapeType int32
+	if err := binary.Read(r, binary.LittleEndian, &shapeType); err != nil {
+		return g, err
+	}
+	switch shapeType {
+	case 0:
+		return g, nil
+	case 1, 11, 21:
+		var xy [2]float64
+		if err := binary.Read(r, binary.LittleEndian, &xy); err != nil {
+			return g, err
+		}
+		g.points = append(g.points, proj(xy[0], xy[1]))
+		return g, nil
+	case 8, 18, 28:
+		parts, err := readShapefileParts(r, false, proj)
+		if err != nil {
+			return g, err
+		}
+		g.points = parts[0]
+		return g, nil
+	case 3, 13, 23:
+		parts, err := readShapefileParts(r, true, proj)
+		g.lines = parts
+		return g, err
+	case 5, 15, 25:
+		parts, err := readShapefileParts(r, true, proj)
+		g.polygons = groupShapefileRings(parts)
+		return g, err
+	}
+	return g, fmt.Errorf("unsupported shape type: %d", shapeType)
+}
+
+// readShapefileParts reads the bounding box, the number of parts (only if hasParts), the number of points, the part
+// indices, and the points of MultiPoints, PolyLines, and Polygons
+func readShapefileParts(r *bytes.Reader, hasParts bool, proj func(x, y float64) s2.LatLng) ([][]s2.LatLng, error) {
+	var box [4]float64
+	if err := binary.Read(r, binary.LittleEndian, &box); err != nil {
+		return nil, err
+	}
+	numParts := int32(1)
+	if hasParts {
+		if err := binary.Read(r, binary.LittleEndian, &numParts); err != nil {
+			return nil, err
+		}
+	}
+	var numPoints int32
+	if err := binary.Read(r, binary.LittleEndian, &numPoints); err != nil {
+		return nil, err
+	}
+	size := int64(numPoints) * 16
+	if hasParts {
+		// MultiPoints have no part indices
+		size += int64(numParts) * 4
+	}
+	if numParts < 0 || numPoints < 0 || size > int64(r.Len()) {
+		return nil, errors.New("number of points exceeds record")
+	}
+	starts := make([]int32, numParts)
+	if hasParts {
+		if err := binary.Read(r, binary.LittleEndian, starts); err != nil {
+			return nil, err
+		}
+	}
+	xy := make([]float64, 2*numPoints)
+	if err := binary.Read(r, binary.LittleEndian, xy); err != nil {
+		return nil, err
+	}
+
+	parts := make([][]s2.LatLng, 0, numParts)
+	for i, start := range starts {
+		end := numPoints
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		if start < 0 || start > end || end > numPoints {
+			return nil, fmt.Errorf("bad part index: %d", start)
+		}
+		part := make([]s2.LatLng, 0, end-start)
+		for j := start; j < end; j++ {
+			part = append(part, proj(xy[2*j], xy[2*j+1]))
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// groupShapefileRings groups the rings of a Polygon record into polygons: each clockwise ring starts a new polygon,
+// and counterclockwise rings (holes) are added to the preceding polygon
+func groupShapefileRings(rings [][]s2.LatLng) [][][]s2.LatLng {
+	polygons := make([][][]s2.LatLng, 0)
+	for _, ring := range rings {
+		if len(polygons) == 0 || ringSignedArea(ring) <= 0 {
+			polygons = append(polygons, [][]s2.LatLng{ring})
+		} else {
+			polygons[len(polygons)-1] = append(polygons[len(polygons)-1], ring)
+		}
+	}
+	return polygons
+}
+
+// ringSignedArea returns twice the signed area of the ring in lng/lat degrees; it is negative for clockwise rings
+func ringSignedArea(ring []s2.LatLng) float64 {
+	area := 0.0
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		area += a.Lng.Degrees()*b.Lat.Degrees() - b.Lng.Degrees()*a.Lat.Degrees()
+	}
+	return area
+}
+
+// readAttributes reads the attributes of the records from the .dbf file
+func (s *Shapefile) readAttributes(dbf []byte) error {
+	if len(dbf) < 32 {
+		return errors.New("bad .dbf file header")
+	}
+	numRecords := int(binary.LittleEndian.Uint32(dbf[4:]))
+	headerLength := int(binary.LittleEndian.Uint16(dbf[8:]))
+	recordLength := int(binary.LittleEndian.Uint16(dbf[10:]))
+	if headerLength > len(dbf) || numRecords != len(s.Records) {
+		return fmt.Errorf("bad .dbf file: %d records, expected %d", numRecords, len(s.Records))
+	}
+
+	// field descriptors (32 bytes each) are terminated by 0x0d
+	lengths := make([]int, 0)
+	for i := 32; i+32 <= headerLength && dbf[i] != 0x0d; i += 32 {
+		s.Fields = append(s.Fields, strings.TrimRight(string(dbf[i:i+11]), "\x00 "))
+		lengths = append(lengths, int(dbf[i+16]))
+	}
+
+	for i := range s.Records {
+		offset := headerLength + i*recordLength
+		if offset+recordLength > len(dbf) {
+			return io.ErrUnexpectedEOF
+		}
+		// the first byte of each record is the deletion flag
+		pos := offset + 1
+		attributes := make(map[string]string, len(s.Fields))
+		for j, name := range s.Fields {
+			if pos+lengths[j] > offset+recordLength {
+				return fmt.Errorf("bad .dbf record %d", i+1)
+			}
+			attributes[name] = strings.TrimSpace(string(dbf[pos : pos+lengths[j]]))
+			pos += lengths[j]
+		}
+		s.Records[i].Attributes = attributes
+	}
+	return nil
+}
+
+// MapObjects converts the geometry of the record to map objects: points become Markers, polylines become Paths, and
+// polygons become Areas (with their holes); the objects are styled by style (nil selects NewFeatureStyle).
+func (r *ShapefileRecord) MapObjects(style *FeatureStyle) []MapObject {
+	return r.geometry.mapObjects(style)
+}
+
+// MapObjects converts the geometries of all records to map objects (see ShapefileRecord.MapObjects).
+func (s *Shapefile) MapObjects(style *FeatureStyle) []MapObject {
+	objects := make([]MapObject, 0, len(s.Records))
+	for i := range s.Records {
+		objects = append(objects, s.Records[i].MapObjects(style)...)
+	}
+	return objects
+}
+
+// loadShapefileObjects loads the map objects of a shapefile for the 'shp:' tokens
+func loadShapefileObjects(fileName string, style *FeatureStyle) ([]MapObject, error) {
+	s, err := LoadShapefile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return s.MapObjects(style), nil
+}
+
+// loadShapefileMarkers loads the points of a shapefile as Markers for the 'shp:' marker token
+func loadShapefileMarkers(fileName string, col color.Color, size float64) ([]*Marker, error) {
+	style := NewFeatureStyle()
+	style.MarkerColor = col
+	style.MarkerSize = size
+	objects, err := loadShapefileObjects(fileName, style)
+	return markersOf(objects), err
+}
+
+// loadShapefilePaths loads the polylines of a shapefile as Paths for the 'shp:' path token
+func loadShapefilePaths(fileName string, col color.Color, weight float64) ([]*Path, error) {
+	style := NewFeatureStyle()
+	style.Stroke = col
+	style.StrokeWidth = weight
+	objects, err := loadShapefileObjects(fileName, style)
+	return pathsOf(objects), err
+}
+
+// loadShapefileAreas loads the polygons of a shapefile as Areas for the 'shp:' area token
+func loadShapefileAreas(fileName string, col color.Color, fill color.Color, weight float64) ([]*Area, error) {
+	style := NewFeatureStyle()
+	style.Stroke = col
+	style.Fill = fill
+	style.StrokeWidth = weight
+	objects, err := loadShapefileObjects(fileName, style)
+	return areasOf(objects), err
+}
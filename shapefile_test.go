@@ -0,0 +1,166 @@
+package sm
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testShapefileRecords are a point, a polygon with a hole (outer ring clockwise, hole counterclockwise), and a
+// multipoint
+var testShapefileRecords = [][]byte{
+	shapefileRecord(1, [][][2]float64{{{13.4, 52.5}}}),
+	shapefileRecord(5, [][][2]float64{
+		{{13, 52}, {13, 53}, {14, 53}, {14, 52}, {13, 52}},
+		{{13.2, 52.2}, {13.8, 52.2}, {13.8, 52.8}, {13.2, 52.8}, {13.2, 52.2}},
+	}),
+	shapefileRecord(8, [][][2]float64{{{13.1, 52.1}, {13.2, 52.2}, {13.3, 52.3}}}),
+}
+
+func shapefileRecord(shapeType int32, parts [][][2]float64) []byte {
+	var b bytes.Buffer
+	write := func(v interface{}) { _ = binary.Write(&b, binary.LittleEndian, v) }
+	write(shapeType)
+	if shapeType == 1 {
+		write(parts[0][0])
+		return b.Bytes()
+	}
+	write([4]float64{})
+	if shapeType == 8 {
+		write(int32(len(parts[0])))
+		write(parts[0])
+		return b.Bytes()
+	}
+	write(int32(len(parts)))
+	numPoints := 0
+	for _, part := range parts {
+		numPoints += len(part)
+	}
+	write(int32(numPoints))
+	start := 0
+	for _, part := range parts {
+		write(int32(start))
+		start += len(part)
+	}
+	for _, part := range parts {
+		write(part)
+	}
+	return b.Bytes()
+}
+
+// writeTestShapefile writes the .shp, .shx, .dbf, and (if not empty) .prj files and returns their contents
+func writeTestShapefile(t *testing.T, base string, prj string) map[string][]byte {
+	var shp, shx bytes.Buffer
+	header := func(b *bytes.Buffer, length int) {
+		_ = binary.Write(b, binary.BigEndian, [7]int32{9994, 0, 0, 0, 0, 0, int32(length / 2)})
+		_ = binary.Write(b, binary.LittleEndian, [2]int32{1000, 5})
+		_ = binary.Write(b, binary.LittleEndian, [8]float64{})
+	}
+	shpLength := 100
+	for _, record := range testShapefileRecords {
+		shpLength += 8 + len(record)
+	}
+	header(&shp, shpLength)
+	header(&shx, 100+8*len(testShapefileRecords))
+	for i, record := range testShapefileRecords {
+		_ = binary.Write(&shx, binary.BigEndian, [2]int32{int32(shp.Len() / 2), int32(len(record) / 2)})
+		_ = binary.Write(&shp, binary.BigEndian, [2]int32{int32(i + 1), int32(len(record) / 2)})
+		shp.Write(record)
+	}
+
+	var dbf bytes.Buffer
+	_ = binary.Write(&dbf, binary.LittleEndian, struct {
+		Version      uint8
+		Date         [3]uint8
+		NumRecords   uint32
+		HeaderLength uint16
+		RecordLength uint16
+		Reserved     [20]uint8
+	}{3, [3]uint8{126, 1, 1}, 3, 32 + 32 + 1, 1 + 10, [20]uint8{}})
+	field := make([]byte, 32)
+	copy(field, "NAME")
+	field[11], field[16] = 'C', 10
+	dbf.Write(field)
+	dbf.WriteString("\x0d" + " Berlin    " + " Park      " + " Trees     " + "\x1a")
+
+	files := map[string][]byte{".shp": shp.Bytes(), ".shx": shx.Bytes(), ".dbf": dbf.Bytes()}
+	if prj != "" {
+		files[".prj"] = []byte(prj)
+	}
+	for ext, data := range files {
+		if err := os.WriteFile(base+ext, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return files
+}
+
+func TestLoadShapefile(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "test")
+	files := writeTestShapefile(t, base, `GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]],PRIMEM["Greenwich",0.0],UNIT["Degree",0.0174532925199433]]`)
+
+	zipName := base + ".zip"
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	for ext, data := range files {
+		f, _ := w.Create("data/test" + ext)
+		_, _ = f.Write(data)
+	}
+	_ = w.Close()
+	if err := os.WriteFile(zipName, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fileName := range []string{base + ".shp", zipName} {
+		s, err := LoadShapefile(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(s.Fields) != 1 || len(s.Records) != 3 || s.Records[0].Attributes["NAME"] != "Berlin" || s.Records[1].Attributes["NAME"] != "Park" {
+			t.Fatalf("unexpected shapefile: %v", s)
+		}
+		if g := s.Records[1].geometry; len(g.polygons) != 1 || len(g.polygons[0]) != 2 {
+			t.Errorf("unexpected polygons: %v", g.polygons)
+		}
+		if g := s.Records[2].geometry; len(g.points) != 3 {
+			t.Errorf("unexpected multipoint: %v", g.points)
+		}
+		objects := s.MapObjects(nil)
+		if len(objects) != 5 {
+			t.Fatalf("unexpected number of objects: %d", len(objects))
+		}
+		if area, ok := objects[1].(*Area); !ok || len(area.Positions) != 4 || len(area.Rings) != 1 {
+			t.Errorf("unexpected area: %v", objects[1])
+		}
+	}
+
+	areas, err := ParseAreasString("color:red|shp:" + base + ".shp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(areas) != 1 {
+		t.Errorf("unexpected areas: %v", areas)
+	}
+}
+
+func TestLoadShapefileProjection(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "test")
+	writeTestShapefile(t, base, `PROJCS["WGS_1984_Web_Mercator_Auxiliary_Sphere",GEOGCS["GCS_WGS_1984",DATUM["D_WGS_1984",SPHEROID["WGS_1984",6378137.0,298.257223563]]],PROJECTION["Mercator_Auxiliary_Sphere"]]`)
+	s, err := LoadShapefile(base + ".shp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the test coordinates are interpreted as meters, i.e. they are very close to (0, 0)
+	if pos := s.Records[0].geometry.points[0]; math.Abs(pos.Lat.Degrees()) > 0.001 || math.Abs(pos.Lng.Degrees()) > 0.001 {
+		t.Errorf("unexpected position: %v", pos)
+	}
+
+	writeTestShapefile(t, base, `PROJCS["ETRS_1989_UTM_Zone_33N",GEOGCS["GCS_ETRS_1989"],PROJECTION["Transverse_Mercator"]]`)
+	if _, err := LoadShapefile(base + ".shp"); err == nil {
+		t.Error("error expected for unsupported CRS")
+	}
+}
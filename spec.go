@@ -0,0 +1,572 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/flopp/go-coordsparser"
+	"github.com/fogleman/gg"
+	"github.com/golang/geo/s2"
+	"gopkg.in/yaml.v3"
+)
+
+// MapSpec is a declarative description of a map, which can be stored as JSON or YAML document. Coordinates are given as
+// "LAT,LNG" strings (in any format accepted by the command line options), colors as color strings (see ParseColorString).
+type MapSpec struct {
+	Width    int                `json:"width,omitempty" yaml:"width,omitempty"`
+	Height   int                `json:"height,omitempty" yaml:"height,omitempty"`
+	Provider *TileProviderSpec  `json:"provider,omitempty" yaml:"provider,omitempty"`
+	Overlays []TileProviderSpec `json:"overlays,omitempty" yaml:"overlays,omitempty"`
+	Center   string             `json:"center,omitempty" yaml:"center,omitempty"`
+	Zoom     *int               `json:"zoom,omitempty" yaml:"zoom,omitempty"`
+	// BBox is the bounding box given as [NW, SE] coordinates
+	BBox       []string `json:"bbox,omitempty" yaml:"bbox,omitempty"`
+	Background string   `json:"background,omitempty" yaml:"background,omitempty"`
+	// Attribution overrides the tile provider's attribution; an empty string disables the attribution
+	Attribution *string      `json:"attribution,omitempty" yaml:"attribution,omitempty"`
+	Objects     []ObjectSpec `json:"objects,omitempty" yaml:"objects,omitempty"`
+}
+
+// TileProviderSpec specifies a tile provider either by the name of a predefined tile provider (see GetTileProviders), or
+// by a custom URL pattern (see TileProvider); in documents, a plain string is interpreted as name of a predefined provider.
+type TileProviderSpec struct {
+	Name           string   `json:"name,omitempty" yaml:"name,omitempty"`
+	URL            string   `json:"url,omitempty" yaml:"url,omitempty"`
+	Attribution    string   `json:"attribution,omitempty" yaml:"attribution,omitempty"`
+	TileSize       int      `json:"tilesize,omitempty" yaml:"tilesize,omitempty"`
+	Shards         []string `json:"shards,omitempty" yaml:"shards,omitempty"`
+	IgnoreNotFound bool     `json:"ignorenotfound,omitempty" yaml:"ignorenotfound,omitempty"`
+	APIKey         string   `json:"apikey,omitempty" yaml:"apikey,omitempty"`
+}
+
+// ObjectSpec specifies a map object; Type is one of "marker", "imagemarker", "path", "area", "circle", "ellipse", and
+// "sector". Markers, image markers, circles, ellipses, and sectors use Position, paths and areas use Positions. Unset
+// styles get the same defaults as with the ParseXxxString functions.
+type ObjectSpec struct {
+	Type         string   `json:"type" yaml:"type"`
+	Position     string   `json:"position,omitempty" yaml:"position,omitempty"`
+	Positions    []string `json:"positions,omitempty" yaml:"positions,omitempty"`
+	Color        string   `json:"color,omitempty" yaml:"color,omitempty"`
+	Fill         string   `json:"fill,omitempty" yaml:"fill,omitempty"`
+	Weight       *float64 `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Size         float64  `json:"size,omitempty" yaml:"size,omitempty"`
+	Label        string   `json:"label,omitempty" yaml:"label,omitempty"`
+	LabelColor   string   `json:"labelcolor,omitempty" yaml:"labelcolor,omitempty"`
+	LabelXOffset float64  `json:"labelxoffset,omitempty" yaml:"labelxoffset,omitempty"`
+	LabelYOffset float64  `json:"labelyoffset,omitempty" yaml:"labelyoffset,omitempty"`
+	Image        string   `json:"image,omitempty" yaml:"image,omitempty"`
+	OffsetX      float64  `json:"offsetx,omitempty" yaml:"offsetx,omitempty"`
+	OffsetY      float64  `json:"offsety,omitempty" yaml:"offsety,omitempty"`
+	// Radius is given in meters
+	Radius float64 `json:"radius,omitempty" yaml:"radius,omitempty"`
+	// SemiMajor and SemiMinor are given in meters, Rotation in degrees
+	SemiMajor float64 `json:"semimajor,omitempty" yaml:"semimajor,omitempty"`
+	SemiMinor float64 `json:"semiminor,omitempty" yaml:"semiminor,omitempty"`
+	Rotation  float64 `json:"rotation,omitempty" yaml:"rotation,omitempty"`
+	// StartBearing and EndBearing are given in degrees
+	StartBearing float64 `json:"startbearing,omitempty" yaml:"startbearing,omitempty"`
+	EndBearing   float64 `json:"endbearing,omitempty" yaml:"endbearing,omitempty"`
+	// Rings are additional rings (holes or further parts) of areas, which are filled according to FillRule ("nonzero",
+	// the default, or "evenodd")
+	Rings    [][]string `json:"rings,omitempty" yaml:"rings,omitempty"`
+	FillRule string     `json:"fillrule,omitempty" yaml:"fillrule,omitempty"`
+}
+
+// tileProviderSpecFields has the same fields as TileProviderSpec, but without the custom (un)marshalling
+type tileProviderSpecFields TileProviderSpec
+
+func (p TileProviderSpec) isNameOnly() bool {
+	return reflect.DeepEqual(p, TileProviderSpec{Name: p.Name})
+}
+
+// MarshalJSON encodes the tile provider as plain name string, if possible.
+func (p TileProviderSpec) MarshalJSON() ([]byte, error) {
+	if p.isNameOnly() {
+		return json.Marshal(p.Name)
+	}
+	return json.Marshal(tileProviderSpecFields(p))
+}
+
+// UnmarshalJSON decodes the tile provider from a plain name string or an object.
+func (p *TileProviderSpec) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*p = TileProviderSpec{Name: name}
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode((*tileProviderSpecFields)(p))
+}
+
+// MarshalYAML encodes the tile provider as plain name string, if possible.
+func (p TileProviderSpec) MarshalYAML() (interface{}, error) {
+	if p.isNameOnly() {
+		return p.Name, nil
+	}
+	return tileProviderSpecFields(p), nil
+}
+
+// UnmarshalYAML decodes the tile provider from a plain name string or a mapping.
+func (p *TileProviderSpec) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*p = TileProviderSpec{Name: value.Value}
+		return nil
+	}
+	return value.Decode((*tileProviderSpecFields)(p))
+}
+
+// DecodeMapSpecJSON reads a map spec from a JSON document; unknown fields are rejected.
+func DecodeMapSpecJSON(r io.Reader) (*MapSpec, error) {
+	spec := new(MapSpec)
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// DecodeMapSpecYAML reads a map spec from a YAML document; unknown fields are rejected.
+func DecodeMapSpecYAML(r io.Reader) (*MapSpec, error) {
+	spec := new(MapSpec)
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// LoadMapSpec reads a map spec from a JSON (".json") or YAML (".yaml", ".yml") file.
+func LoadMapSpec(fileName string) (*MapSpec, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".json":
+		return DecodeMapSpecJSON(file)
+	case ".yaml", ".yml":
+		return DecodeMapSpecYAML(file)
+	}
+	return nil, fmt.Errorf("unknown map spec format: '%s'", fileName)
+}
+
+// EncodeJSON writes the map spec as JSON document to w.
+func (s *MapSpec) EncodeJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// EncodeYAML writes the map spec as YAML document to w.
+func (s *MapSpec) EncodeYAML(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(s); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// Save writes the map spec to a JSON (".json") or YAML (".yaml", ".yml") file.
+func (s *MapSpec) Save(fileName string) error {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".json":
+		return saveFile(fileName, s.EncodeJSON)
+	case ".yaml", ".yml":
+		return saveFile(fileName, s.EncodeYAML)
+	}
+	return fmt.Errorf("unknown map spec format: '%s'", fileName)
+}
+
+// NewContextFromSpec creates a Context as described by the map spec.
+func NewContextFromSpec(spec *MapSpec) (*Context, error) {
+	ctx := NewContext()
+	if spec.Width > 0 || spec.Height > 0 {
+		if spec.Width <= 0 || spec.Height <= 0 {
+			return nil, fmt.Errorf("invalid size: %dx%d", spec.Width, spec.Height)
+		}
+		ctx.SetSize(spec.Width, spec.Height)
+	}
+	if spec.Provider != nil {
+		t, err := spec.Provider.tileProvider()
+		if err != nil {
+			return nil, err
+		}
+		ctx.SetTileProvider(t)
+	}
+	for i := range spec.Overlays {
+		t, err := spec.Overlays[i].tileProvider()
+		if err != nil {
+			return nil, err
+		}
+		ctx.AddOverlay(t)
+	}
+
+	if spec.Center != "" {
+		center, err := parseSpecLatLng(spec.Center)
+		if err != nil {
+			return nil, err
+		}
+		ctx.SetCenter(center)
+	}
+	if spec.Zoom != nil {
+		ctx.SetZoom(*spec.Zoom)
+	}
+	if spec.BBox != nil {
+		bbox, err := parseSpecBBox(spec.BBox)
+		if err != nil {
+			return nil, err
+		}
+		ctx.SetBoundingBox(*bbox)
+	}
+	if spec.Background != "" {
+		background, err := ParseColorString(spec.Background)
+		if err != nil {
+			return nil, err
+		}
+		ctx.SetBackground(background)
+	}
+	if spec.Attribution != nil {
+		ctx.OverrideAttribution(*spec.Attribution)
+	}
+
+	for i, objectSpec := range spec.Objects {
+		object, err := objectSpec.mapObject()
+		if err != nil {
+			return nil, fmt.Errorf("object %d: %v", i, err)
+		}
+		ctx.AddObject(object)
+	}
+	return ctx, nil
+}
+
+// parseSpecBBox parses the [NW, SE] coordinates of a bounding box
+func parseSpecBBox(coordinates []string) (*s2.Rect, error) {
+	if len(coordinates) != 2 {
+		return nil, fmt.Errorf("bad bbox: expected [NW, SE] coordinates, got %d coordinates", len(coordinates))
+	}
+	nw, err := parseSpecLatLng(coordinates[0])
+	if err != nil {
+		return nil, err
+	}
+	se, err := parseSpecLatLng(coordinates[1])
+	if err != nil {
+		return nil, err
+	}
+	return CreateBBox(nw.Lat.Degrees(), nw.Lng.Degrees(), se.Lat.Degrees(), se.Lng.Degrees())
+}
+
+// Spec returns the map spec describing the Context; it fails for map objects that cannot be described (e.g. custom
+// MapObject implementations, or image markers, whose image file is unknown).
+func (m *Context) Spec() (*MapSpec, error) {
+	spec := &MapSpec{Width: m.width, Height: m.height}
+	provider := tileProviderSpec(m.tileProvider)
+	spec.Provider = &provider
+	for _, overlay := range m.overlays {
+		spec.Overlays = append(spec.Overlays, tileProviderSpec(overlay))
+	}
+	if m.hasCenter {
+		spec.Center = specLatLng(m.center)
+	}
+	if m.hasZoom {
+		zoom := m.zoom
+		spec.Zoom = &zoom
+	}
+	if m.hasBoundingBox {
+		spec.BBox = []string{specLatLng(m.boundingBox.Vertex(3)), specLatLng(m.boundingBox.Vertex(1))}
+	}
+	if m.background != nil {
+		spec.Background = specColor(m.background)
+	}
+	if m.overrideAttribution != nil {
+		attribution := *m.overrideAttribution
+		spec.Attribution = &attribution
+	}
+
+	for i, object := range m.objects {
+		objectSpec, err := newObjectSpec(object)
+		if err != nil {
+			return nil, fmt.Errorf("object %d: %v", i, err)
+		}
+		spec.Objects = append(spec.Objects, objectSpec)
+	}
+	return spec, nil
+}
+
+func (p *TileProviderSpec) tileProvider() (*TileProvider, error) {
+	if p.URL == "" {
+		t := GetTileProviders(p.APIKey)[p.Name]
+		if t == nil {
+			return nil, fmt.Errorf("unknown tile provider: '%s'", p.Name)
+		}
+		return t, nil
+	}
+
+	t := new(TileProvider)
+	t.Name = p.Name
+	t.Attribution = p.Attribution
+	t.TileSize = p.TileSize
+	if t.TileSize <= 0 {
+		t.TileSize = 256
+	}
+	t.URLPattern = p.URL
+	t.Shards = p.Shards
+	t.IgnoreNotFound = p.IgnoreNotFound
+	t.APIKey = p.APIKey
+	return t, nil
+}
+
+// tileProviderSpec describes the tile provider by name, if it is one of the predefined tile providers
+func tileProviderSpec(t *TileProvider) TileProviderSpec {
+	if predefined := GetTileProviders(t.APIKey)[t.Name]; predefined != nil && reflect.DeepEqual(*predefined, *t) {
+		return TileProviderSpec{Name: t.Name, APIKey: t.APIKey}
+	}
+	return TileProviderSpec{
+		Name:           t.Name,
+		URL:            t.URLPattern,
+		Attribution:    t.Attribution,
+		TileSize:       t.TileSize,
+		Shards:         t.Shards,
+		IgnoreNotFound: t.IgnoreNotFound,
+		APIKey:         t.APIKey,
+	}
+}
+
+func (o *ObjectSpec) mapObject() (MapObject, error) {
+	switch o.Type {
+	case "marker":
+		return o.marker()
+	case "imagemarker":
+		return o.imageMarker()
+	case "path", "area":
+		return o.pathOrArea()
+	case "circle", "ellipse", "sector":
+		return o.circular()
+	}
+	return nil, fmt.Errorf("unknown object type: '%s'", o.Type)
+}
+
+func (o *ObjectSpec) marker() (MapObject, error) {
+	position, err := parseSpecLatLng(o.Position)
+	if err != nil {
+		return nil, err
+	}
+	col, err := parseSpecColor(o.Color, color.RGBA{0xff, 0, 0, 0xff})
+	if err != nil {
+		return nil, err
+	}
+	size := o.Size
+	if size == 0 {
+		size = 16.0
+	}
+	marker := NewMarker(position, col, size)
+	marker.Label = o.Label
+	if o.LabelColor != "" {
+		labelColor, err := ParseColorString(o.LabelColor)
+		if err != nil {
+			return nil, err
+		}
+		marker.SetLabelColor(labelColor)
+	}
+	if o.LabelXOffset != 0 {
+		marker.LabelXOffset = o.LabelXOffset
+	}
+	if o.LabelYOffset != 0 {
+		marker.LabelYOffset = o.LabelYOffset
+	}
+	return marker, nil
+}
+
+func (o *ObjectSpec) imageMarker() (MapObject, error) {
+	position, err := parseSpecLatLng(o.Position)
+	if err != nil {
+		return nil, err
+	}
+	if o.Image == "" {
+		return nil, fmt.Errorf("cannot create an ImageMarker without an image")
+	}
+	file, err := os.Open(o.Image)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewImageMarker(position, img, o.OffsetX, o.OffsetY), nil
+}
+
+func (o *ObjectSpec) pathOrArea() (MapObject, error) {
+	positions, err := parseSpecLatLngs(o.Positions)
+	if err != nil {
+		return nil, err
+	}
+	col, err := parseSpecColor(o.Color, color.RGBA{0xff, 0, 0, 0xff})
+	if err != nil {
+		return nil, err
+	}
+	weight := o.weight()
+	if o.Type == "path" {
+		return NewPath(positions, col, weight), nil
+	}
+	fill, err := parseSpecColor(o.Fill, color.Transparent)
+	if err != nil {
+		return nil, err
+	}
+	area := NewArea(positions, col, fill, weight)
+	for _, ring := range o.Rings {
+		positions, err := parseSpecLatLngs(ring)
+		if err != nil {
+			return nil, err
+		}
+		area.Rings = append(area.Rings, positions)
+	}
+	if o.FillRule != "" {
+		if area.FillRule, err = parseFillRule(o.FillRule); err != nil {
+			return nil, err
+		}
+	}
+	return area, nil
+}
+
+func (o *ObjectSpec) circular() (MapObject, error) {
+	position, err := parseSpecLatLng(o.Position)
+	if err != nil {
+		return nil, err
+	}
+	col, err := parseSpecColor(o.Color, color.RGBA{0xff, 0, 0, 0xff})
+	if err != nil {
+		return nil, err
+	}
+	fill, err := parseSpecColor(o.Fill, color.Transparent)
+	if err != nil {
+		return nil, err
+	}
+	radius := o.Radius
+	if radius == 0 {
+		radius = 100.0
+	}
+	switch o.Type {
+	case "ellipse":
+		return NewEllipse(position, col, fill, o.SemiMajor, o.SemiMinor, o.Rotation, o.weight()), nil
+	case "sector":
+		return NewSector(position, col, fill, radius, o.StartBearing, o.EndBearing, o.weight()), nil
+	}
+	return NewCircle(position, col, fill, radius, o.weight()), nil
+}
+
+// parseSpecColor parses the color string; an empty string yields the default color
+func parseSpecColor(s string, def color.Color) (color.Color, error) {
+	if s == "" {
+		return def, nil
+	}
+	return ParseColorString(s)
+}
+
+func (o *ObjectSpec) weight() float64 {
+	if o.Weight == nil {
+		return 5.0
+	}
+	return *o.Weight
+}
+
+func newObjectSpec(object MapObject) (ObjectSpec, error) {
+	switch o := object.(type) {
+	case *Marker:
+		return ObjectSpec{Type: "marker", Position: specLatLng(o.Position), Color: specColor(o.Color), Size: o.Size,
+			Label: o.Label, LabelColor: specColor(o.LabelColor), LabelXOffset: o.LabelXOffset, LabelYOffset: o.LabelYOffset}, nil
+	case *Path:
+		return ObjectSpec{Type: "path", Positions: specLatLngs(o.Positions), Color: specColor(o.Color), Weight: specWeight(o.Weight)}, nil
+	case *Area:
+		spec := ObjectSpec{Type: "area", Positions: specLatLngs(o.Positions), Color: specColor(o.Color), Fill: specColor(o.Fill),
+			Weight: specWeight(o.Weight)}
+		for _, ring := range o.Rings {
+			spec.Rings = append(spec.Rings, specLatLngs(ring))
+		}
+		if o.FillRule != gg.FillRuleWinding {
+			spec.FillRule = fillRuleString(o.FillRule)
+		}
+		return spec, nil
+	case *Circle:
+		return ObjectSpec{Type: "circle", Position: specLatLng(o.Position), Color: specColor(o.Color), Fill: specColor(o.Fill),
+			Weight: specWeight(o.Weight), Radius: o.Radius}, nil
+	case *Ellipse:
+		return ObjectSpec{Type: "ellipse", Position: specLatLng(o.Position), Color: specColor(o.Color), Fill: specColor(o.Fill),
+			Weight: specWeight(o.Weight), SemiMajor: o.SemiMajor, SemiMinor: o.SemiMinor, Rotation: o.Rotation}, nil
+	case *Sector:
+		return ObjectSpec{Type: "sector", Position: specLatLng(o.Position), Color: specColor(o.Color), Fill: specColor(o.Fill),
+			Weight: specWeight(o.Weight), Radius: o.Radius, StartBearing: o.StartBearing, EndBearing: o.EndBearing}, nil
+	case *ImageMarker:
+		return ObjectSpec{}, fmt.Errorf("cannot describe image marker: image file unknown")
+	}
+	return ObjectSpec{}, fmt.Errorf("cannot describe object of type %T", object)
+}
+
+func parseSpecLatLng(s string) (s2.LatLng, error) {
+	lat, lng, err := coordsparser.Parse(s)
+	if err != nil {
+		return s2.LatLng{}, err
+	}
+	return s2.LatLngFromDegrees(lat, lng), nil
+}
+
+func parseSpecLatLngs(s []string) ([]s2.LatLng, error) {
+	positions := make([]s2.LatLng, 0, len(s))
+	for _, ss := range s {
+		position, err := parseSpecLatLng(ss)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+func specLatLng(ll s2.LatLng) string {
+	return strconv.FormatFloat(ll.Lat.Degrees(), 'f', -1, 64) + "," + strconv.FormatFloat(ll.Lng.Degrees(), 'f', -1, 64)
+}
+
+func specLatLngs(positions []s2.LatLng) []string {
+	s := make([]string, 0, len(positions))
+	for _, ll := range positions {
+		s = append(s, specLatLng(ll))
+	}
+	return s
+}
+
+// specColor returns the color as "#RRGGBBAA" string; like ParseColorString, color.RGBA values are taken as is
+func specColor(col color.Color) string {
+	if col == nil {
+		return ""
+	}
+	c, ok := col.(color.RGBA)
+	if !ok {
+		n := color.NRGBAModel.Convert(col).(color.NRGBA)
+		c = color.RGBA{n.R, n.G, n.B, n.A}
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+func specWeight(weight float64) *float64 {
+	return &weight
+}
@@ -0,0 +1,99 @@
+package sm
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+const testMapSpecYAML = `width: 640
+height: 480
+provider: carto-light_all
+overlays:
+  - name: custom
+    url: https://tiles.example.com/%[2]d/%[3]d/%[4]d.png
+    attribution: Example
+    tilesize: 256
+center: 52.5,13.4
+zoom: 12
+background: '#ffffffff'
+attribution: ""
+objects:
+  - type: marker
+    position: 52.51,13.41
+    color: '#0000ffff'
+    size: 12
+    label: A
+    labelcolor: '#ffffffff'
+    labelxoffset: 0.5
+    labelyoffset: 0.5
+  - type: path
+    positions:
+      - 52.5,13.4
+      - 52.6,13.5
+    color: '#ff0000ff'
+    weight: 3
+  - type: circle
+    position: 52.5,13.4
+    color: '#ff0000ff'
+    fill: '#ff000040'
+    weight: 0
+    radius: 250
+`
+
+func TestMapSpecRoundTrip(t *testing.T) {
+	spec, err := DecodeMapSpecYAML(strings.NewReader(testMapSpecYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := NewContextFromSpec(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.tileProvider.Name != "carto-light_all" || len(ctx.overlays) != 1 || ctx.overlays[0].URLPattern != "https://tiles.example.com/%[2]d/%[3]d/%[4]d.png" {
+		t.Errorf("unexpected tile providers: %v %v", ctx.tileProvider, ctx.overlays)
+	}
+	if len(ctx.objects) != 3 || ctx.Attribution() != "" {
+		t.Fatalf("unexpected context: %d objects, attribution '%s'", len(ctx.objects), ctx.Attribution())
+	}
+	if circle, ok := ctx.objects[2].(*Circle); !ok || circle.Weight != 0 || circle.Fill != (color.RGBA{0xff, 0, 0, 0x40}) {
+		t.Errorf("unexpected circle: %v", ctx.objects[2])
+	}
+
+	spec2, err := ctx.Spec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := spec2.EncodeYAML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != testMapSpecYAML {
+		t.Errorf("unexpected YAML:\n%s\nexpected:\n%s", buf.String(), testMapSpecYAML)
+	}
+}
+
+func TestMapSpecJSON(t *testing.T) {
+	spec, err := DecodeMapSpecJSON(strings.NewReader(`{"provider": "osm", "objects": [{"type": "area", "positions": ["1,2", "3,4", "1,4"], "fill": "red"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := NewContextFromSpec(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	area, ok := ctx.objects[0].(*Area)
+	if !ok || len(area.Positions) != 3 || area.Weight != 5.0 || area.Positions[1] != s2.LatLngFromDegrees(3, 4) {
+		t.Errorf("unexpected area: %v", ctx.objects[0])
+	}
+
+	if _, err := DecodeMapSpecJSON(strings.NewReader(`{"widht": 100}`)); err == nil {
+		t.Error("error expected for unknown field")
+	}
+	if _, err := NewContextFromSpec(&MapSpec{Objects: []ObjectSpec{{Type: "polygon"}}}); err == nil {
+		t.Error("error expected for unknown object type")
+	}
+}
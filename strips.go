@@ -0,0 +1,297 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"bufio"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+)
+
+// RenderStrips renders the map image in horizontal strips of (at most) stripHeight rows, which are passed to fn from top to
+// bottom along with their y offset; only a single strip is held in memory at a time, and the strip image is re-used, i.e. it is
+// only valid during the call of fn. Tiles and map objects crossing strip edges are drawn consistently, such that the strips
+// compose the same image as Render (up to tiny anti-aliasing differences, as gg snaps coordinates to 1/64 pixels).
+func (m *Context) RenderStrips(stripHeight int, fn func(strip *image.RGBA, y int) error) error {
+	if stripHeight <= 0 {
+		return fmt.Errorf("invalid strip height: %d", stripHeight)
+	}
+	zoom, trans, err := m.croppedTransformer()
+	if err != nil {
+		return err
+	}
+
+	// strips are rendered with some extra rows above and below, since the anti-aliasing of shapes is less accurate at the
+	// image edges
+	const pad = 2
+	// tiles are shared by consecutive strips, so they are kept in a memory cache (unless the context has one anyway)
+	if m.memoryCache == nil {
+		c := *m
+		c.memoryCache = NewMemoryTileCache(m.stripTileCapacity(stripHeight + 2*pad))
+		m = &c
+	}
+	area := image.Rect(0, 0, m.width, m.height)
+	var img *image.RGBA
+	for y := 0; y < m.height; y += stripHeight {
+		h := stripHeight
+		if y+h > m.height {
+			h = m.height - y
+		}
+		if img == nil || img.Rect.Dy() != h+2*pad {
+			img = image.NewRGBA(image.Rect(0, 0, m.width, h+2*pad))
+		} else {
+			for i := range img.Pix {
+				img.Pix[i] = 0
+			}
+		}
+		if err := m.renderTo(img, zoom, trans.window(0, y-pad, m.width, h+2*pad), area.Sub(image.Pt(0, y-pad))); err != nil {
+			return err
+		}
+		strip := &image.RGBA{Pix: img.Pix[pad*img.Stride:], Stride: img.Stride, Rect: image.Rect(0, 0, m.width, h)}
+		if err := fn(strip, y); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stripTileCapacity returns the number of tiles (of all layers) covering two consecutive strips of the given height
+func (m *Context) stripTileCapacity(height int) int {
+	tileSize := m.tileProvider.TileSize
+	for _, overlay := range m.overlays {
+		if overlay.TileSize < tileSize {
+			tileSize = overlay.TileSize
+		}
+	}
+	if tileSize <= 0 {
+		tileSize = 256
+	}
+	columns := m.width/tileSize + 2
+	rows := 2*height/tileSize + 2
+	return columns * rows * (1 + len(m.overlays))
+}
+
+// RenderPNGStrips renders the map image in strips of stripHeight rows (see RenderStrips) and streams them as RGBA PNG image to w.
+func (m *Context) RenderPNGStrips(w io.Writer, stripHeight int) error {
+	out := bufio.NewWriter(w)
+	out.WriteString("\x89PNG\r\n\x1a\n")
+	// 8 bit depth, color type RGBA, default compression and filter methods, no interlacing
+	header := append(pngUint32s(uint32(m.width), uint32(m.height)), 8, 6, 0, 0, 0)
+	if err := writePNGChunk(out, "IHDR", header); err != nil {
+		return err
+	}
+
+	idat := &pngDataWriter{w: out}
+	z := zlib.NewWriter(idat)
+	filter := newPNGFilter(m.width)
+	err := m.RenderStrips(stripHeight, func(strip *image.RGBA, y int) error {
+		for row := 0; row < strip.Rect.Dy(); row++ {
+			if _, err := z.Write(filter.filter(strip, row)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := z.Close(); err != nil {
+		return err
+	}
+	if err := idat.Flush(); err != nil {
+		return err
+	}
+	if err := writePNGChunk(out, "IEND", nil); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// RenderGeoTIFFStrips renders the map image in strips of stripHeight rows (see RenderStrips) and streams them as GeoTIFF image
+// (see EncodeGeoTIFF) to w; since the TIFF header has to be updated after all strips have been written, w needs to be seekable.
+func (m *Context) RenderGeoTIFFStrips(w io.WriteSeeker, stripHeight int) error {
+	_, trans, err := m.croppedTransformer()
+	if err != nil {
+		return err
+	}
+
+	// the IFD offset in the header is updated at the end
+	const headerSize = 8
+	writeTIFFHeader(w, 0)
+	offset := int64(headerSize)
+	var offsets, counts []uint32
+	err = m.RenderStrips(stripHeight, func(strip *image.RGBA, y int) error {
+		data, err := compressTIFFStrip(strip, strip.Rect)
+		if err != nil {
+			return err
+		}
+		// TIFF offsets are 32 bit, i.e. the strips and the IFD following them must not exceed 4GB
+		if offset+int64(len(data)) > math.MaxUint32 {
+			return errors.New("GeoTIFF exceeds the maximum file size of 4GB")
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		offsets = append(offsets, uint32(offset))
+		counts = append(counts, uint32(len(data)))
+		offset += int64(len(data))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if offset%2 != 0 {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return err
+		}
+		offset++
+	}
+	ifd := encodeTIFFIFD(int(offset), geoTIFFEntries(m.width, m.height, stripHeight, offsets, counts, trans))
+	if offset+int64(len(ifd)) > math.MaxUint32 {
+		return errors.New("GeoTIFF exceeds the maximum file size of 4GB")
+	}
+	if _, err := w.Write(ifd); err != nil {
+		return err
+	}
+	if _, err := w.Seek(4, io.SeekStart); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(offset)); err != nil {
+		return err
+	}
+	_, err = w.Seek(0, io.SeekEnd)
+	return err
+}
+
+// pngDataWriter writes the data as sequence of IDAT chunks
+type pngDataWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+// pngChunkSize is the maximum size of IDAT chunks written by pngDataWriter
+const pngChunkSize = 1 << 16
+
+// Write buffers the data and writes complete IDAT chunks.
+func (d *pngDataWriter) Write(p []byte) (int, error) {
+	d.buf = append(d.buf, p...)
+	for len(d.buf) >= pngChunkSize {
+		if err := writePNGChunk(d.w, "IDAT", d.buf[:pngChunkSize]); err != nil {
+			return 0, err
+		}
+		d.buf = d.buf[pngChunkSize:]
+	}
+	return len(p), nil
+}
+
+// Flush writes the remaining data as IDAT chunk.
+func (d *pngDataWriter) Flush() error {
+	if len(d.buf) == 0 {
+		return nil
+	}
+	err := writePNGChunk(d.w, "IDAT", d.buf)
+	d.buf = nil
+	return err
+}
+
+// pngFilter converts image rows to filtered PNG scanlines; it keeps the previous row, as needed by the Up, Average, and Paeth filters
+type pngFilter struct {
+	prev, cur []byte
+	filtered  [5][]byte
+}
+
+func newPNGFilter(width int) *pngFilter {
+	f := new(pngFilter)
+	f.prev = make([]byte, 4*width)
+	f.cur = make([]byte, 4*width)
+	for i := range f.filtered {
+		f.filtered[i] = make([]byte, 1+4*width)
+		f.filtered[i][0] = byte(i)
+	}
+	return f
+}
+
+// filter returns the filter type byte followed by the filtered (non-premultiplied) pixels of the image row; like Go's PNG
+// encoder, the filter with the smallest sum of absolute differences is chosen.
+func (f *pngFilter) filter(img *image.RGBA, row int) []byte {
+	pix := img.Pix[row*img.Stride : row*img.Stride+4*img.Rect.Dx()]
+	for i := 0; i < len(pix); i += 4 {
+		r, g, b, a := pix[i], pix[i+1], pix[i+2], pix[i+3]
+		if a != 0 && a != 0xff {
+			r = uint8(uint32(r) * 0xff / uint32(a))
+			g = uint8(uint32(g) * 0xff / uint32(a))
+			b = uint8(uint32(b) * 0xff / uint32(a))
+		}
+		f.cur[i], f.cur[i+1], f.cur[i+2], f.cur[i+3] = r, g, b, a
+	}
+
+	best, bestSum := 0, -1
+	for i := range f.filtered {
+		sum := f.apply(i, f.filtered[i][1:])
+		if bestSum < 0 || sum < bestSum {
+			best, bestSum = i, sum
+		}
+	}
+	f.prev, f.cur = f.cur, f.prev
+	return f.filtered[best]
+}
+
+// apply computes the scanline with the filter type ft, and returns the sum of the absolute (signed) filtered values
+func (f *pngFilter) apply(ft int, out []byte) int {
+	const bpp = 4
+	sum := 0
+	for i, x := range f.cur {
+		var left, up, upLeft byte
+		if i >= bpp {
+			left = f.cur[i-bpp]
+			upLeft = f.prev[i-bpp]
+		}
+		up = f.prev[i]
+		switch ft {
+		case 0:
+			out[i] = x
+		case 1:
+			out[i] = x - left
+		case 2:
+			out[i] = x - up
+		case 3:
+			out[i] = x - byte((int(left)+int(up))/2)
+		case 4:
+			out[i] = x - paeth(left, up, upLeft)
+		}
+		v := int(int8(out[i]))
+		if v < 0 {
+			v = -v
+		}
+		sum += v
+	}
+	return sum
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,129 @@
+package sm
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"golang.org/x/image/tiff"
+)
+
+func newStripsTestContext() *Context {
+	ctx := NewContext()
+	ctx.SetSize(120, 90)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.SetBackground(color.RGBA{0, 0x80, 0, 0x80})
+	ctx.OverrideAttribution("strips")
+	ctx.AddObject(NewPath([]s2.LatLng{s2.LatLngFromDegrees(48.0, 7.0), s2.LatLngFromDegrees(48.2, 7.2)}, color.RGBA{0, 0, 255, 255}, 6.0))
+	ctx.AddObject(NewMarker(s2.LatLngFromDegrees(48.1, 7.1), color.RGBA{255, 0, 0, 255}, 16.0))
+	return ctx
+}
+
+// sameImage compares the images; due to gg's snapping of coordinates to 1/64 pixels, the anti-aliasing of shapes may
+// slightly differ, if the images are rendered with different offsets
+func sameImage(t *testing.T, expected image.Image, actual image.Image) {
+	if expected.Bounds() != actual.Bounds() {
+		t.Fatalf("unexpected bounds: %v, expected %v", actual.Bounds(), expected.Bounds())
+	}
+	b := expected.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r1, g1, b1, a1 := expected.At(x, y).RGBA()
+			r2, g2, b2, a2 := actual.At(x, y).RGBA()
+			if diff(r1, r2) > 8 || diff(g1, g2) > 8 || diff(b1, b2) > 8 || diff(a1, a2) > 8 {
+				t.Fatalf("pixel %d/%d differs: %v, expected %v", x, y, actual.At(x, y), expected.At(x, y))
+			}
+		}
+	}
+}
+
+func TestRenderPNGStrips(t *testing.T) {
+	ctx := newStripsTestContext()
+	expected, err := ctx.Render()
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := ctx.RenderPNGStrips(&buf, 7); err != nil {
+		t.Fatalf("failed to render strips: %v", err)
+	}
+	actual, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	sameImage(t, expected, actual)
+}
+
+func TestRenderGeoTIFFStrips(t *testing.T) {
+	ctx := newStripsTestContext()
+	expected, err := ctx.Render()
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	fileName := filepath.Join(t.TempDir(), "map.tif")
+	file, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := ctx.RenderGeoTIFFStrips(file, 16); err != nil {
+		t.Fatalf("failed to render strips: %v", err)
+	}
+	file.Close()
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := tiff.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	sameImage(t, expected, actual)
+}
+
+// diff returns the absolute difference of the 8 bit values of the color channels
+func diff(v1, v2 uint32) uint32 {
+	if v1 > v2 {
+		return (v1 - v2) >> 8
+	}
+	return (v2 - v1) >> 8
+}
+
+func TestRenderStripsFetchesTilesOnce(t *testing.T) {
+	var mutex sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests[r.URL.Path]++
+		mutex.Unlock()
+		_ = png.Encode(w, image.NewRGBA(image.Rect(0, 0, 256, 256)))
+	}))
+	defer server.Close()
+
+	ctx := newStripsTestContext()
+	ctx.SetCache(nil)
+	provider := &TileProvider{Name: "test", TileSize: 256, URLPattern: server.URL + "/%[2]d/%[3]d/%[4]d.png%[1]s", Shards: []string{""}}
+	ctx.SetTileProvider(provider)
+	if err := ctx.RenderStrips(7, func(strip *image.RGBA, y int) error { return nil }); err != nil {
+		t.Fatalf("failed to render strips: %v", err)
+	}
+	if len(requests) == 0 {
+		t.Fatal("no tiles fetched")
+	}
+	for path, n := range requests {
+		if n != 1 {
+			t.Errorf("tile %s fetched %d times", path, n)
+		}
+	}
+}
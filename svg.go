@@ -0,0 +1,280 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// svgCanvas is a Canvas that writes SVG elements
+type svgCanvas struct {
+	w         *bufio.Writer
+	path      strings.Builder
+	hasPath   bool
+	current   bool
+	color     color.Color
+	lineWidth float64
+	lineCap   gg.LineCap
+	lineJoin  gg.LineJoin
+	fillRule  gg.FillRule
+	face      font.Face
+	custom    bool
+	// err is the first error that occurred while drawing, e.g. when encoding an image
+	err error
+}
+
+func newSVGCanvas(w *bufio.Writer) *svgCanvas {
+	c := new(svgCanvas)
+	c.w = w
+	c.color = color.Black
+	c.lineWidth = 1.0
+	c.lineCap = gg.LineCapRound
+	c.lineJoin = gg.LineJoinRound
+	c.face = basicfont.Face7x13
+	return c
+}
+
+// ClearPath clears the current path.
+func (c *svgCanvas) ClearPath() {
+	c.path.Reset()
+	c.hasPath = false
+	c.current = false
+}
+
+// MoveTo starts a new subpath at the given point.
+func (c *svgCanvas) MoveTo(x, y float64) {
+	fmt.Fprintf(&c.path, "M%s %s ", svgNumber(x), svgNumber(y))
+	c.hasPath = true
+	c.current = true
+}
+
+// LineTo adds a line segment to the current path; it starts a new subpath if there is no current point.
+func (c *svgCanvas) LineTo(x, y float64) {
+	if !c.current {
+		c.MoveTo(x, y)
+		return
+	}
+	fmt.Fprintf(&c.path, "L%s %s ", svgNumber(x), svgNumber(y))
+}
+
+// ClosePath closes the current subpath.
+func (c *svgCanvas) ClosePath() {
+	if c.hasPath {
+		c.path.WriteString("Z ")
+		c.current = false
+	}
+}
+
+// DrawArc adds a circular arc from angle1 to angle2 (radians, clockwise) to the current path.
+func (c *svgCanvas) DrawArc(x, y, r, angle1, angle2 float64) {
+	c.LineTo(x+r*math.Cos(angle1), y+r*math.Sin(angle1))
+	// split into arcs of at most 180°, since SVG arcs are specified by their end points
+	const n = 4
+	for i := 1; i <= n; i++ {
+		a := angle1 + (angle2-angle1)*float64(i)/n
+		sweep := 1
+		if angle2 < angle1 {
+			sweep = 0
+		}
+		fmt.Fprintf(&c.path, "A%s %s 0 0 %d %s %s ", svgNumber(r), svgNumber(r), sweep, svgNumber(x+r*math.Cos(a)), svgNumber(y+r*math.Sin(a)))
+	}
+}
+
+// DrawRectangle adds a closed rectangle subpath to the current path.
+func (c *svgCanvas) DrawRectangle(x, y, w, h float64) {
+	c.MoveTo(x, y)
+	c.LineTo(x+w, y)
+	c.LineTo(x+w, y+h)
+	c.LineTo(x, y+h)
+	c.ClosePath()
+}
+
+// SetLineWidth sets the line width for stroking.
+func (c *svgCanvas) SetLineWidth(lineWidth float64) {
+	c.lineWidth = lineWidth
+}
+
+// SetLineCap sets the line cap style for stroking.
+func (c *svgCanvas) SetLineCap(lineCap gg.LineCap) {
+	c.lineCap = lineCap
+}
+
+// SetLineJoin sets the line join style for stroking.
+func (c *svgCanvas) SetLineJoin(lineJoin gg.LineJoin) {
+	c.lineJoin = lineJoin
+}
+
+// SetColor sets the color for filling, stroking, and text.
+func (c *svgCanvas) SetColor(col color.Color) {
+	c.color = col
+}
+
+// SetFillRule sets the fill rule, which determines the inside of self-intersecting paths and paths with multiple subpaths.
+func (c *svgCanvas) SetFillRule(fillRule gg.FillRule) {
+	c.fillRule = fillRule
+}
+
+// SetFontFace sets the font face that is used for measuring text; the SVG text is rendered with a generic font family of the same size.
+func (c *svgCanvas) SetFontFace(fontFace font.Face) {
+	c.face = fontFace
+	c.custom = true
+}
+
+// Fill fills the current path and clears it afterwards.
+func (c *svgCanvas) Fill() {
+	c.FillPreserve()
+	c.ClearPath()
+}
+
+// FillPreserve fills the current path without clearing it.
+func (c *svgCanvas) FillPreserve() {
+	if !c.hasPath || isTransparent(c.color) {
+		return
+	}
+	fillRule := ""
+	if c.fillRule == gg.FillRuleEvenOdd {
+		fillRule = ` fill-rule="evenodd"`
+	}
+	fmt.Fprintf(c.w, `<path d="%s" %s%s stroke="none"/>`+"\n", strings.TrimSpace(c.path.String()), svgColor(c.color, "fill"), fillRule)
+}
+
+// Stroke strokes the current path and clears it afterwards.
+func (c *svgCanvas) Stroke() {
+	if c.hasPath && !isTransparent(c.color) && c.lineWidth > 0 {
+		fmt.Fprintf(c.w, `<path d="%s" fill="none" %s stroke-width="%s" stroke-linecap="%s" stroke-linejoin="%s"/>`+"\n",
+			strings.TrimSpace(c.path.String()), svgColor(c.color, "stroke"), svgNumber(c.lineWidth), svgLineCap(c.lineCap), svgLineJoin(c.lineJoin))
+	}
+	c.ClearPath()
+}
+
+// DrawImage draws the image with its top left corner at the given point.
+func (c *svgCanvas) DrawImage(im image.Image, x, y int) {
+	if err := writeSVGImage(c.w, im, x, y); err != nil && c.err == nil {
+		c.err = err
+	}
+}
+
+// DrawStringAnchored draws the text at the given point; ax, ay specify the relative anchor position within the text's bounding box.
+func (c *svgCanvas) DrawStringAnchored(s string, x, y, ax, ay float64) {
+	if isTransparent(c.color) {
+		return
+	}
+	w, h := c.MeasureString(s)
+	x -= ax * w
+	y += ay * h
+	family := "monospace"
+	if c.custom {
+		family = "sans-serif"
+	}
+	// the line height of typical fonts is ~1.2 times their size
+	fmt.Fprintf(c.w, `<text x="%s" y="%s" font-family="%s" font-size="%s" textLength="%s" %s>%s</text>`+"\n",
+		svgNumber(x), svgNumber(y), family, svgNumber(h/1.2), svgNumber(w), svgColor(c.color, "fill"), html.EscapeString(s))
+}
+
+// MeasureString returns the width and height of the rendered text.
+func (c *svgCanvas) MeasureString(s string) (float64, float64) {
+	d := &font.Drawer{Face: c.face}
+	return float64(d.MeasureString(s)) / 64, float64(c.face.Metrics().Height) / 64
+}
+
+func isTransparent(col color.Color) bool {
+	_, _, _, a := col.RGBA()
+	return a == 0
+}
+
+// svgNumber formats a number with up to two decimals
+func svgNumber(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// svgColor returns the color and opacity attributes for the given property ("fill" or "stroke")
+func svgColor(col color.Color, property string) string {
+	c := color.NRGBAModel.Convert(col).(color.NRGBA)
+	if c.A == 0xff {
+		return fmt.Sprintf(`%s="#%02x%02x%02x"`, property, c.R, c.G, c.B)
+	}
+	return fmt.Sprintf(`%s="#%02x%02x%02x" %s-opacity="%s"`, property, c.R, c.G, c.B, property, svgNumber(float64(c.A)/255.0))
+}
+
+func svgLineCap(lineCap gg.LineCap) string {
+	switch lineCap {
+	case gg.LineCapRound:
+		return "round"
+	case gg.LineCapButt:
+		return "butt"
+	case gg.LineCapSquare:
+		return "square"
+	}
+	return "round"
+}
+
+func svgLineJoin(lineJoin gg.LineJoin) string {
+	switch lineJoin {
+	case gg.LineJoinRound:
+		return "round"
+	case gg.LineJoinBevel:
+		return "bevel"
+	}
+	return "round"
+}
+
+// writeSVGImage embeds the image as a base64 encoded PNG
+func writeSVGImage(w io.Writer, im image.Image, x, y int) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, im); err != nil {
+		return err
+	}
+	size := im.Bounds().Size()
+	_, err := fmt.Fprintf(w, `<image x="%d" y="%d" width="%d" height="%d" xlink:href="data:image/png;base64,%s"/>`+"\n",
+		x, y, size.X, size.Y, base64.StdEncoding.EncodeToString(buf.Bytes()))
+	return err
+}
+
+// RenderSVG renders the map as SVG document to w.
+//
+// The map tiles (and the background) are embedded as a single raster image; map objects implementing CanvasObject
+// (e.g. markers, paths, areas, circles) as well as the attribution are written as SVG elements. Other map objects are
+// rasterized and embedded as images.
+func (m *Context) RenderSVG(w io.Writer) error {
+	zoom, trans, err := m.croppedTransformer()
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		m.width, m.height, m.width, m.height)
+	canvas := newSVGCanvas(out)
+	if err := m.drawToCanvas(canvas, zoom, trans); err != nil {
+		return err
+	}
+	if canvas.err != nil {
+		return canvas.err
+	}
+	fmt.Fprintf(out, "</svg>\n")
+	return out.Flush()
+}
@@ -0,0 +1,65 @@
+package sm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/color"
+	"io"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestRenderSVG(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetSize(400, 300)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.OverrideAttribution("attribution <&>")
+
+	coords1 := s2.LatLngFromDegrees(48.123, 7.0)
+	coords2 := s2.LatLngFromDegrees(48.987, 8.0)
+	ctx.AddObject(NewPath([]s2.LatLng{coords1, coords2}, color.RGBA{0, 0, 255, 255}, 4.0))
+	ctx.AddObject(NewMarker(coords1, color.RGBA{255, 0, 0, 255}, 16.0))
+	ctx.AddObject(NewImageMarker(coords2, image.NewRGBA(image.Rect(0, 0, 8, 8)), 4.0, 4.0))
+
+	var buf bytes.Buffer
+	if err := ctx.RenderSVG(&buf); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	elements := make(map[string]int)
+	decoder := xml.NewDecoder(&buf)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			elements[start.Name.Local]++
+		}
+	}
+
+	// path: 1 path stroke, 1 marker fill + 1 marker stroke, 1 attribution box
+	if elements["svg"] != 1 || elements["path"] != 4 || elements["text"] != 1 || elements["image"] != 1 {
+		t.Errorf("unexpected SVG elements: %v", elements)
+	}
+}
+
+func TestSVGCanvasImageError(t *testing.T) {
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	canvas := newSVGCanvas(out)
+	canvas.DrawImage(image.NewRGBA(image.Rect(0, 0, 4, 4)), 0, 0)
+	if canvas.err != nil {
+		t.Fatalf("unexpected error: %v", canvas.err)
+	}
+	canvas.DrawImage(image.NewRGBA(image.Rect(0, 0, 0, 0)), 0, 0)
+	if canvas.err == nil {
+		t.Error("error expected for empty image")
+	}
+}
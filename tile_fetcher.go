@@ -23,12 +23,16 @@ import (
 
 var errTileNotFound = errors.New("error 404: tile not found")
 
+// defaultUserAgent is the HTTP user agent string used for downloads, unless overridden
+const defaultUserAgent = "Mozilla/5.0+(compatible; go-staticmaps/0.1; https://github.com/flopp/go-staticmaps)"
+
 // TileFetcher downloads map tile images from a TileProvider
 type TileFetcher struct {
 	tileProvider *TileProvider
 	cache        TileCache
 	userAgent    string
 	online       bool
+	memoryCache  *MemoryTileCache
 }
 
 // Tile defines a single map tile
@@ -42,7 +46,7 @@ func NewTileFetcher(tileProvider *TileProvider, cache TileCache, online bool) *T
 	t := new(TileFetcher)
 	t.tileProvider = tileProvider
 	t.cache = cache
-	t.userAgent = "Mozilla/5.0+(compatible; go-staticmaps/0.1; https://github.com/flopp/go-staticmaps)"
+	t.userAgent = defaultUserAgent
 	t.online = online
 	return t
 }
@@ -52,6 +56,11 @@ func (t *TileFetcher) SetUserAgent(a string) {
 	t.userAgent = a
 }
 
+// SetMemoryCache sets a cache of decoded tile images, which is consulted before the (file) tile cache; nil disables it
+func (t *TileFetcher) SetMemoryCache(cache *MemoryTileCache) {
+	t.memoryCache = cache
+}
+
 func (t *TileFetcher) url(zoom, x, y int) string {
 	shard := ""
 	ss := len(t.tileProvider.Shards)
@@ -73,6 +82,23 @@ func cacheFileName(cache TileCache, providerName string, zoom, x, y int) string
 
 // Fetch download (or retrieves from the cache) a tile image for the specified zoom level and tile coordinates
 func (t *TileFetcher) Fetch(tile *Tile) error {
+	if t.memoryCache == nil {
+		return t.fetch(tile)
+	}
+
+	key := memoryTileKey{t.tileProvider.Name, t.tileProvider.URLPattern, tile.Zoom, tile.X, tile.Y}
+	if img := t.memoryCache.get(key); img != nil {
+		tile.Img = img
+		return nil
+	}
+	if err := t.fetch(tile); err != nil {
+		return err
+	}
+	t.memoryCache.put(key, tile.Img)
+	return nil
+}
+
+func (t *TileFetcher) fetch(tile *Tile) error {
 	if t.cache != nil {
 		fileName := cacheFileName(t.cache, t.tileProvider.Name, tile.Zoom, tile.X, tile.Y)
 		cachedImg, err := t.loadCache(fileName)
@@ -0,0 +1,75 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"container/list"
+	"image"
+	"sync"
+)
+
+// MemoryTileCache keeps the most recently used decoded tile images in memory; it is safe for concurrent use, such that
+// it can be shared by multiple Contexts (e.g. rendering many maps in parallel) to avoid re-loading and re-decoding tiles.
+type MemoryTileCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[memoryTileKey]*list.Element
+	lru      *list.List
+}
+
+type memoryTileKey struct {
+	provider   string
+	urlPattern string
+	zoom, x, y int
+}
+
+type memoryTileEntry struct {
+	key memoryTileKey
+	img image.Image
+}
+
+// NewMemoryTileCache creates a MemoryTileCache holding up to capacity tiles (a 256x256 pixel tile takes 256KB).
+func NewMemoryTileCache(capacity int) *MemoryTileCache {
+	c := new(MemoryTileCache)
+	c.capacity = capacity
+	c.entries = make(map[memoryTileKey]*list.Element)
+	c.lru = list.New()
+	return c
+}
+
+// Len returns the number of cached tiles.
+func (c *MemoryTileCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Len()
+}
+
+func (c *MemoryTileCache) get(key memoryTileKey) image.Image {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(element)
+	return element.Value.(*memoryTileEntry).img
+}
+
+func (c *MemoryTileCache) put(key memoryTileKey, img image.Image) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*memoryTileEntry).img = img
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&memoryTileEntry{key, img})
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		delete(c.entries, oldest.Value.(*memoryTileEntry).key)
+		c.lru.Remove(oldest)
+	}
+}
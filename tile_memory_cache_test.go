@@ -0,0 +1,35 @@
+package sm
+
+import (
+	"image"
+	"testing"
+)
+
+func TestMemoryTileCache(t *testing.T) {
+	cache := NewMemoryTileCache(2)
+	provider := NewTileProviderOpenStreetMaps()
+	fetcher := NewTileFetcher(provider, nil, false)
+	fetcher.SetMemoryCache(cache)
+
+	if err := fetcher.Fetch(&Tile{Zoom: 1, X: 0, Y: 0}); err == nil {
+		t.Error("error expected for offline fetcher without cached tile")
+	}
+
+	key := func(x int) memoryTileKey {
+		return memoryTileKey{provider.Name, provider.URLPattern, 1, x, 0}
+	}
+	images := []image.Image{image.NewRGBA(image.Rect(0, 0, 1, 1)), image.NewRGBA(image.Rect(0, 0, 2, 2)), image.NewRGBA(image.Rect(0, 0, 3, 3))}
+	cache.put(key(0), images[0])
+	cache.put(key(1), images[1])
+
+	tile := &Tile{Zoom: 1, X: 0, Y: 0}
+	if err := fetcher.Fetch(tile); err != nil || tile.Img != images[0] {
+		t.Errorf("cached tile expected: %v", err)
+	}
+
+	// tile 0 has been used more recently than tile 1, which is evicted
+	cache.put(key(2), images[2])
+	if cache.Len() != 2 || cache.get(key(0)) != images[0] || cache.get(key(1)) != nil || cache.get(key(2)) != images[2] {
+		t.Errorf("unexpected cache state: %d tiles", cache.Len())
+	}
+}
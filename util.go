@@ -16,3 +16,12 @@ func hasPrefix(s string, prefix string) (bool, string) {
 	}
 	return false, s
 }
+
+// floorDiv computes floor(a / b) for integers; b must be positive.
+func floorDiv(a int, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,196 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/s2"
+)
+
+// EWKB flags of the geometry type
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// ParseWKB converts a WKB or EWKB (i.e. PostGIS' extended WKB with optional SRID) geometry to map objects; the
+// geometries are converted and styled like the respective WKT geometries (see ParseWKT).
+func ParseWKB(data []byte, style *FeatureStyle) ([]MapObject, error) {
+	r := &wkbReader{r: bytes.NewReader(data)}
+	var g simpleGeometry
+	if err := r.readGeometry(&g, nil); err != nil {
+		return nil, err
+	}
+	if r.r.Len() > 0 {
+		return nil, fmt.Errorf("bad WKB: %d trailing bytes", r.r.Len())
+	}
+	return g.mapObjects(style), nil
+}
+
+type wkbReader struct {
+	r     *bytes.Reader
+	order binary.ByteOrder
+	// dims is the number of coordinates per position of the current geometry
+	dims int
+}
+
+func (r *wkbReader) read(v interface{}) error {
+	if err := binary.Read(r.r, r.order, v); err != nil {
+		return fmt.Errorf("bad WKB: %v", err)
+	}
+	return nil
+}
+
+// readHeader reads the byte order and the geometry type (ISO or EWKB flavor), and returns the base geometry type and
+// the SRID (0 if not given)
+func (r *wkbReader) readHeader() (uint32, int, error) {
+	order, err := r.r.ReadByte()
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad WKB: %v", err)
+	}
+	switch order {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return 0, 0, fmt.Errorf("bad WKB byte order: %d", order)
+	}
+
+	var geometryType uint32
+	if err := r.read(&geometryType); err != nil {
+		return 0, 0, err
+	}
+	r.dims = 2
+	if geometryType&ewkbZ != 0 {
+		r.dims++
+	}
+	if geometryType&ewkbM != 0 {
+		r.dims++
+	}
+	srid := 0
+	if geometryType&ewkbSRID != 0 {
+		var s uint32
+		if err := r.read(&s); err != nil {
+			return 0, 0, err
+		}
+		srid = int(s)
+	}
+	geometryType &^= ewkbZ | ewkbM | ewkbSRID
+	// ISO WKB encodes Z, M, and ZM as offsets 1000, 2000, and 3000
+	switch geometryType / 1000 {
+	case 1, 2:
+		r.dims++
+	case 3:
+		r.dims += 2
+	}
+	return geometryType % 1000, srid, nil
+}
+
+// readGeometry reads a geometry; proj is inherited from the enclosing geometry, or nil for the top-level geometry
+func (r *wkbReader) readGeometry(g *simpleGeometry, proj func(x, y float64) s2.LatLng) error {
+	geometryType, srid, err := r.readHeader()
+	if err != nil {
+		return err
+	}
+	if proj == nil || srid != 0 {
+		if proj, err = sridProjection(srid); err != nil {
+			return err
+		}
+	}
+
+	switch geometryType {
+	case 1:
+		pos, err := r.readPosition(proj)
+		if err != nil {
+			return err
+		}
+		// empty points are encoded with NaN coordinates
+		if !math.IsNaN(pos.Lat.Degrees()) {
+			g.points = append(g.points, pos)
+		}
+		return nil
+	case 2:
+		line, err := r.readPositions(proj)
+		if err != nil {
+			return err
+		}
+		g.lines = append(g.lines, line)
+		return nil
+	case 3:
+		rings, err := r.readRings(proj)
+		if err != nil {
+			return err
+		}
+		g.polygons = append(g.polygons, rings)
+		return nil
+	case 4, 5, 6, 7:
+		return r.readCollection(g, proj)
+	}
+	return fmt.Errorf("unsupported WKB geometry type: %d", geometryType)
+}
+
+// readCollection reads the geometries of a MultiPoint, MultiLineString, MultiPolygon, or GeometryCollection
+func (r *wkbReader) readCollection(g *simpleGeometry, proj func(x, y float64) s2.LatLng) error {
+	var n uint32
+	if err := r.read(&n); err != nil {
+		return err
+	}
+	for i := uint32(0); i < n; i++ {
+		if err := r.readGeometry(g, proj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *wkbReader) readPosition(proj func(x, y float64) s2.LatLng) (s2.LatLng, error) {
+	values := make([]float64, r.dims)
+	if err := r.read(values); err != nil {
+		return s2.LatLng{}, err
+	}
+	return proj(values[0], values[1]), nil
+}
+
+func (r *wkbReader) readPositions(proj func(x, y float64) s2.LatLng) ([]s2.LatLng, error) {
+	var n uint32
+	if err := r.read(&n); err != nil {
+		return nil, err
+	}
+	if int64(n)*int64(8*r.dims) > int64(r.r.Len()) {
+		return nil, fmt.Errorf("bad WKB: %d positions exceed the data", n)
+	}
+	positions := make([]s2.LatLng, 0, n)
+	for i := uint32(0); i < n; i++ {
+		pos, err := r.readPosition(proj)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, pos)
+	}
+	return positions, nil
+}
+
+func (r *wkbReader) readRings(proj func(x, y float64) s2.LatLng) ([][]s2.LatLng, error) {
+	var n uint32
+	if err := r.read(&n); err != nil {
+		return nil, err
+	}
+	rings := make([][]s2.LatLng, 0)
+	for i := uint32(0); i < n; i++ {
+		ring, err := r.readPositions(proj)
+		if err != nil {
+			return nil, err
+		}
+		rings = append(rings, ring)
+	}
+	return rings, nil
+}
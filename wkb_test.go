@@ -0,0 +1,41 @@
+package sm
+
+import (
+	"encoding/hex"
+	"math"
+	"testing"
+)
+
+func TestParseWKB(t *testing.T) {
+	// POLYGON ((13 52, 14 52, 14 53, 13 52), (13.4 52.2, 13.6 52.2, 13.6 52.4, 13.4 52.2)), little endian
+	polygon, _ := hex.DecodeString("010300000002000000040000000000000000002a400000000000004a400000000000002c400000000000004a400000000000002c400000000000804a400000000000002a400000000000004a4004000000cdcccccccccc2a409a99999999194a403333333333332b409a99999999194a403333333333332b403333333333334a40cdcccccccccc2a409a99999999194a40")
+	objects, err := ParseWKB(polygon, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("unexpected number of objects: %d", len(objects))
+	}
+	if area, ok := objects[0].(*Area); !ok || len(area.Positions) != 3 || area.Positions[2].Lat.Degrees() != 53 {
+		t.Errorf("unexpected area: %v", objects[0])
+	}
+
+	// SRID=3857;MULTIPOINT Z ((0 0 10), (1113194.9079327357 0 10)), big endian EWKB
+	multiPoint, _ := hex.DecodeString("00a000000400000f1100000002008000000100000000000000000000000000000000402400000000000000800000014130fc6ae86e479f00000000000000004024000000000000")
+	objects, err = ParseWKB(multiPoint, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("unexpected number of objects: %d", len(objects))
+	}
+	if marker, ok := objects[1].(*Marker); !ok || math.Abs(marker.Position.Lng.Degrees()-10.0) > 1e-9 {
+		t.Errorf("unexpected marker: %v", objects[1])
+	}
+
+	for _, bad := range [][]byte{polygon[:20], multiPoint[:9], append(polygon, 0), {2, 1, 0, 0, 0}} {
+		if _, err := ParseWKB(bad, nil); err == nil {
+			t.Errorf("error expected for %x", bad)
+		}
+	}
+}
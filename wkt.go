@@ -0,0 +1,313 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang/geo/s2"
+)
+
+// simpleGeometry collects the points, lines, and polygons (outer ring followed by the inner rings) of a simple features
+// geometry, as parsed from WKT or WKB
+type simpleGeometry struct {
+	points   []s2.LatLng
+	lines    [][]s2.LatLng
+	polygons [][][]s2.LatLng
+}
+
+// mapObjects converts points to Markers, lines to Paths, and polygons to Areas (with their holes)
+func (g *simpleGeometry) mapObjects(style *FeatureStyle) []MapObject {
+	if style == nil {
+		style = NewFeatureStyle()
+	}
+	objects := make([]MapObject, 0, len(g.points)+len(g.lines)+len(g.polygons))
+	for _, pos := range g.points {
+		objects = append(objects, NewMarker(pos, style.MarkerColor, style.MarkerSize))
+	}
+	for _, line := range g.lines {
+		objects = append(objects, NewPath(line, style.Stroke, style.StrokeWidth))
+	}
+	for _, polygon := range g.polygons {
+		if len(polygon) == 0 {
+			continue
+		}
+		objects = append(objects, newRingsArea(polygon, style.Stroke, style.Fill, style.StrokeWidth))
+	}
+	return objects
+}
+
+// sridProjection returns the conversion of x/y coordinates of the spatial reference system to LatLng; supported are
+// WGS84 (SRID 4326, or 0 if unspecified) and Web Mercator (SRID 3857 and its aliases)
+func sridProjection(srid int) (func(x, y float64) s2.LatLng, error) {
+	switch srid {
+	case 0, 4326:
+		return wgs84ToLatLng, nil
+	case 3857, 3785, 900913, 102100, 102113:
+		return webMercatorToLatLng, nil
+	}
+	return nil, fmt.Errorf("unsupported SRID: %d", srid)
+}
+
+// wgs84ToLatLng converts WGS84 coordinates (longitude, latitude) to LatLng
+func wgs84ToLatLng(x, y float64) s2.LatLng {
+	return s2.LatLngFromDegrees(y, x)
+}
+
+// webMercatorToLatLng converts Web Mercator coordinates (meters) to LatLng
+func webMercatorToLatLng(x, y float64) s2.LatLng {
+	const r = 6378137.0
+	return s2.LatLngFromDegrees(math.Atan(math.Sinh(y/r))*180.0/math.Pi, x/r*180.0/math.Pi)
+}
+
+// LoadWKT loads a file with one geometry per line, each given as WKT/EWKT or as hex encoded WKB/EWKB (e.g. the output
+// of a PostGIS query), and converts the geometries to map objects (see ParseWKT).
+func LoadWKT(fileName string, style *FeatureStyle) ([]MapObject, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	objects := make([]MapObject, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" {
+			continue
+		}
+		var lineObjects []MapObject
+		if data, err := hex.DecodeString(s); err == nil {
+			lineObjects, err = ParseWKB(data, style)
+		} else {
+			lineObjects, err = ParseWKT(s, style)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", fileName, line, err)
+		}
+		objects = append(objects, lineObjects...)
+	}
+	return objects, scanner.Err()
+}
+
+// ParseWKT converts a WKT or EWKT (i.e. with an "SRID=...;" prefix) geometry to map objects: POINTs and MULTIPOINTs
+// become Markers, LINESTRINGs and MULTILINESTRINGs become Paths, and POLYGONs and MULTIPOLYGONs become Areas (with their
+// holes); GEOMETRYCOLLECTIONs are converted recursively. Z and M values are ignored. Coordinates are expected in
+// WGS84 or, if given by the SRID, Web Mercator. The objects are styled by style (nil selects NewFeatureStyle).
+func ParseWKT(s string, style *FeatureStyle) ([]MapObject, error) {
+	srid := 0
+	if ok, suffix := hasPrefix(strings.ToUpper(strings.TrimSpace(s)), "SRID="); ok {
+		i := strings.Index(suffix, ";")
+		if i < 0 {
+			return nil, fmt.Errorf("bad EWKT: missing ';' after SRID")
+		}
+		var err error
+		if srid, err = strconv.Atoi(suffix[:i]); err != nil {
+			return nil, fmt.Errorf("bad EWKT SRID: '%s'", suffix[:i])
+		}
+		s = suffix[i+1:]
+	}
+	proj, err := sridProjection(srid)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &wktParser{s: s, proj: proj}
+	var g simpleGeometry
+	if err := p.parseGeometry(&g); err != nil {
+		return nil, err
+	}
+	if token := p.next(); token != "" {
+		return nil, fmt.Errorf("bad WKT: unexpected '%s'", token)
+	}
+	return g.mapObjects(style), nil
+}
+
+type wktParser struct {
+	s    string
+	pos  int
+	proj func(x, y float64) s2.LatLng
+}
+
+// next returns the next token, i.e. "(", ")", ",", a word or a number; it returns "" at the end of the string
+func (p *wktParser) next() string {
+	for p.pos < len(p.s) && strings.ContainsRune(" \t\r\n", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	if p.pos >= len(p.s) {
+		return ""
+	}
+	start := p.pos
+	if strings.ContainsRune("(),", rune(p.s[p.pos])) {
+		p.pos++
+		return p.s[start:p.pos]
+	}
+	for p.pos < len(p.s) && !strings.ContainsRune(" \t\r\n(),", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	return strings.ToUpper(p.s[start:p.pos])
+}
+
+func (p *wktParser) peek() string {
+	pos := p.pos
+	token := p.next()
+	p.pos = pos
+	return token
+}
+
+func (p *wktParser) expect(expected string) error {
+	if token := p.next(); token != expected {
+		return fmt.Errorf("bad WKT: expected '%s', got '%s'", expected, token)
+	}
+	return nil
+}
+
+// parseList parses a parenthesized, comma separated list, calling item for each element
+func (p *wktParser) parseList(item func() error) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		if token := p.next(); token == ")" {
+			return nil
+		} else if token != "," {
+			return fmt.Errorf("bad WKT: expected ',' or ')', got '%s'", token)
+		}
+	}
+}
+
+func (p *wktParser) parseGeometry(g *simpleGeometry) error {
+	geometryType := p.next()
+	switch p.peek() {
+	case "Z", "M", "ZM":
+		p.next()
+	}
+	if p.peek() == "EMPTY" {
+		p.next()
+		return nil
+	}
+
+	switch geometryType {
+	case "POINT":
+		return p.parseList(func() error { return p.parsePoint(g) })
+	case "MULTIPOINT":
+		return p.parseList(func() error {
+			// both "MULTIPOINT ((1 2), (3 4))" and "MULTIPOINT (1 2, 3 4)" are valid
+			if p.peek() == "(" {
+				return p.parseList(func() error { return p.parsePoint(g) })
+			}
+			return p.parsePoint(g)
+		})
+	case "LINESTRING":
+		return p.parseLine(g)
+	case "MULTILINESTRING":
+		return p.parseList(func() error { return p.parseLine(g) })
+	case "POLYGON":
+		return p.parsePolygon(g)
+	case "MULTIPOLYGON":
+		return p.parseList(func() error { return p.parsePolygon(g) })
+	case "GEOMETRYCOLLECTION":
+		return p.parseList(func() error { return p.parseGeometry(g) })
+	}
+	return fmt.Errorf("unsupported WKT geometry type: '%s'", geometryType)
+}
+
+// parsePosition parses "X Y [Z [M]]"
+func (p *wktParser) parsePosition() (s2.LatLng, error) {
+	values := make([]float64, 0, 4)
+	for token := p.peek(); token != "" && token != "," && token != ")"; token = p.peek() {
+		v, err := strconv.ParseFloat(p.next(), 64)
+		if err != nil {
+			return s2.LatLng{}, fmt.Errorf("bad WKT coordinate: '%s'", token)
+		}
+		values = append(values, v)
+	}
+	if len(values) < 2 || len(values) > 4 {
+		return s2.LatLng{}, fmt.Errorf("bad WKT position: %v", values)
+	}
+	return p.proj(values[0], values[1]), nil
+}
+
+func (p *wktParser) parsePoint(g *simpleGeometry) error {
+	pos, err := p.parsePosition()
+	if err != nil {
+		return err
+	}
+	g.points = append(g.points, pos)
+	return nil
+}
+
+func (p *wktParser) parsePositions() ([]s2.LatLng, error) {
+	positions := make([]s2.LatLng, 0)
+	err := p.parseList(func() error {
+		pos, err := p.parsePosition()
+		positions = append(positions, pos)
+		return err
+	})
+	return positions, err
+}
+
+func (p *wktParser) parseLine(g *simpleGeometry) error {
+	positions, err := p.parsePositions()
+	if err != nil {
+		return err
+	}
+	g.lines = append(g.lines, positions)
+	return nil
+}
+
+func (p *wktParser) parsePolygon(g *simpleGeometry) error {
+	rings := make([][]s2.LatLng, 0)
+	err := p.parseList(func() error {
+		ring, err := p.parsePositions()
+		rings = append(rings, ring)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	g.polygons = append(g.polygons, rings)
+	return nil
+}
+
+// loadWKTMarkers loads the points of a WKT file as Markers for the 'wkt:' marker token
+func loadWKTMarkers(fileName string, col color.Color, size float64) ([]*Marker, error) {
+	style := NewFeatureStyle()
+	style.MarkerColor = col
+	style.MarkerSize = size
+	objects, err := LoadWKT(fileName, style)
+	return markersOf(objects), err
+}
+
+// loadWKTPaths loads the lines of a WKT file as Paths for the 'wkt:' path token
+func loadWKTPaths(fileName string, col color.Color, weight float64) ([]*Path, error) {
+	style := NewFeatureStyle()
+	style.Stroke = col
+	style.StrokeWidth = weight
+	objects, err := LoadWKT(fileName, style)
+	return pathsOf(objects), err
+}
+
+// loadWKTAreas loads the polygons of a WKT file as Areas for the 'wkt:' area token
+func loadWKTAreas(fileName string, col color.Color, fill color.Color, weight float64) ([]*Area, error) {
+	style := NewFeatureStyle()
+	style.Stroke = col
+	style.Fill = fill
+	style.StrokeWidth = weight
+	objects, err := LoadWKT(fileName, style)
+	return areasOf(objects), err
+}
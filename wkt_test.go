@@ -0,0 +1,69 @@
+package sm
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestParseWKT(t *testing.T) {
+	wkt := `GEOMETRYCOLLECTION (POINT Z (13.4 52.5 10), MULTIPOINT ((1 2), (3 4)), MULTIPOINT (5 6, 7 8),
+		LINESTRING (13.4 52.5, 13.5 52.6), MULTILINESTRING EMPTY,
+		MULTIPOLYGON (((13 52, 14 52, 14 53, 13 52), (13.4 52.2, 13.6 52.2, 13.6 52.4, 13.4 52.2))))`
+	objects, err := ParseWKT(wkt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 7 {
+		t.Fatalf("unexpected number of objects: %d", len(objects))
+	}
+	if marker, ok := objects[0].(*Marker); !ok || marker.Position != s2.LatLngFromDegrees(52.5, 13.4) {
+		t.Errorf("unexpected marker: %v", objects[0])
+	}
+	if path, ok := objects[5].(*Path); !ok || len(path.Positions) != 2 {
+		t.Errorf("unexpected path: %v", objects[5])
+	}
+	if area, ok := objects[6].(*Area); !ok || len(area.Positions) != 3 {
+		t.Errorf("unexpected area: %v", objects[6])
+	}
+
+	objects, err = ParseWKT("SRID=3857;POINT(1113194.9079327357 0)", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marker := objects[0].(*Marker); math.Abs(marker.Position.Lng.Degrees()-10.0) > 1e-9 {
+		t.Errorf("unexpected marker: %v", marker)
+	}
+
+	for _, bad := range []string{"POINT (1)", "POINT (1 2", "LINESTRING (1 2, a 4)", "CIRCLE (1 2)", "POINT (1 2) x", "SRID=31467;POINT (1 2)"} {
+		if _, err := ParseWKT(bad, nil); err == nil {
+			t.Errorf("error expected for '%s'", bad)
+		}
+	}
+}
+
+func TestParsePathStringWKT(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.wkt")
+	data := "LINESTRING (13.4 52.5, 13.5 52.6)\n\nPOINT (1 2)\n01ba0b000002000000000000000000f03f000000000000004000000000000008400000000000001040000000000000144000000000000018400000000000001c400000000000002040\n"
+	if err := os.WriteFile(fileName, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := ParsePathString("weight:2|wkt:" + fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths[0].Weight != 2 || paths[1].Positions[1] != s2.LatLngFromDegrees(6, 5) {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+	markers, err := ParseMarkerString("wkt:" + fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(markers) != 1 {
+		t.Errorf("unexpected markers: %v", markers)
+	}
+}
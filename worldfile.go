@@ -0,0 +1,68 @@
+// Copyright 2026 Florian Pigorsch. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sm
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// webMercatorWKT is the well-known text representation of the web mercator projection (EPSG:3857)
+const webMercatorWKT = `PROJCS["WGS 84 / Pseudo-Mercator",GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563,AUTHORITY["EPSG","7030"]],AUTHORITY["EPSG","6326"]],PRIMEM["Greenwich",0,AUTHORITY["EPSG","8901"]],UNIT["degree",0.0174532925199433,AUTHORITY["EPSG","9122"]],AUTHORITY["EPSG","4326"]],PROJECTION["Mercator_1SP"],PARAMETER["central_meridian",0],PARAMETER["scale_factor",1],PARAMETER["false_easting",0],PARAMETER["false_northing",0],UNIT["metre",1,AUTHORITY["EPSG","9001"]],AXIS["Easting",EAST],AXIS["Northing",NORTH],EXTENSION["PROJ4","+proj=merc +a=6378137 +b=6378137 +lat_ts=0 +lon_0=0 +x_0=0 +y_0=0 +k=1 +units=m +nadgrids=@null +wktext +no_defs"],AUTHORITY["EPSG","3857"]]`
+
+// WriteWorldFile writes an ESRI world file for the image described by trans to w.
+func WriteWorldFile(w io.Writer, trans *Transformer) error {
+	size := trans.PixelSize()
+	// world files refer to the center of the top left pixel
+	x, y := trans.XYToWebMercator(0.5, 0.5)
+	_, err := fmt.Fprintf(w, "%.10f\n0.0000000000\n0.0000000000\n%.10f\n%.10f\n%.10f\n", size, -size, x, y)
+	return err
+}
+
+// WriteAuxXML writes a GDAL PAM (.aux.xml) file with the EPSG:3857 CRS and the geo transform of the image described by trans to w.
+func WriteAuxXML(w io.Writer, trans *Transformer) error {
+	size := trans.PixelSize()
+	// the geo transform refers to the top left corner of the top left pixel
+	x, y := trans.XYToWebMercator(0, 0)
+	_, err := fmt.Fprintf(w, "<PAMDataset>\n  <SRS dataAxisToSRSAxisMapping=\"1,2\">%s</SRS>\n  <GeoTransform>%.10f, %.10f, 0.0000000000, %.10f, 0.0000000000, %.10f</GeoTransform>\n</PAMDataset>\n",
+		webMercatorWKT, x, size, y, -size)
+	return err
+}
+
+// WorldFileName returns the conventional world file name for the image file, e.g. "map.pgw" for "map.png" or "map.jgw" for "map.jpg"; "map.wld" is used for short or missing extensions.
+func WorldFileName(imageFileName string) string {
+	ext := filepath.Ext(imageFileName)
+	base := strings.TrimSuffix(imageFileName, ext)
+	if len(ext) < 3 {
+		return base + ".wld"
+	}
+	return base + ext[:2] + ext[len(ext)-1:] + "w"
+}
+
+// SaveGeoreference writes a world file (see WorldFileName) and a GDAL PAM file (imageFileName + ".aux.xml") next to the
+// image file; trans has to be the Transformer returned together with the image (e.g. by RenderWithOptions).
+func SaveGeoreference(imageFileName string, trans *Transformer) error {
+	if err := saveFile(WorldFileName(imageFileName), func(w io.Writer) error { return WriteWorldFile(w, trans) }); err != nil {
+		return err
+	}
+	return saveFile(imageFileName+".aux.xml", func(w io.Writer) error { return WriteAuxXML(w, trans) })
+}
+
+func saveFile(fileName string, write func(w io.Writer) error) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		return err
+	}
+	return file.Close()
+}
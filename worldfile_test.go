@@ -0,0 +1,43 @@
+package sm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestWorldFileName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"map.png":      "map.pgw",
+		"dir/map.jpeg": "dir/map.jgw",
+		"map.tiff":     "map.tfw",
+		"map":          "map.wld",
+	} {
+		if actual := WorldFileName(name); actual != expected {
+			t.Errorf("WorldFileName(%s): expected %s, got %s", name, expected, actual)
+		}
+	}
+}
+
+func TestWriteWorldFile(t *testing.T) {
+	ctx := NewContext()
+	ctx.SetSize(256, 256)
+	ctx.SetTileProvider(NewTileProviderNone())
+	ctx.SetCenter(s2.LatLngFromDegrees(0, 0))
+	ctx.SetZoom(0)
+	_, trans, err := ctx.RenderWithOptions(RenderOptions{})
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteWorldFile(&buf, trans); err != nil {
+		t.Fatalf("failed to write world file: %v", err)
+	}
+	expected := []string{"156543.0339280410", "0.0000000000", "0.0000000000", "-156543.0339280410", "-19959236.8258252218", "19959236.8258252218"}
+	if actual := strings.Fields(buf.String()); strings.Join(actual, " ") != strings.Join(expected, " ") {
+		t.Errorf("unexpected world file: %v", actual)
+	}
+}